@@ -0,0 +1,105 @@
+package gosolana
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/gagliardetto/solana-go"
+	"github.com/gagliardetto/solana-go/rpc"
+)
+
+const (
+	// MaxMultipleAccounts getMultipleAccounts 单次请求最多支持的账户数量
+	MaxMultipleAccounts = 100
+	// DefaultMultipleAccountsConcurrency 分块查询时默认的最大并发数
+	DefaultMultipleAccountsConcurrency = 4
+)
+
+// AccountResult 单个账户的查询结果
+type AccountResult struct {
+	PublicKey solana.PublicKey
+	Account   *rpc.Account // 账户不存在时为 nil
+}
+
+// Exists 账户是否存在
+func (r AccountResult) Exists() bool {
+	return r.Account != nil
+}
+
+// GetMultipleAccounts 查询任意数量的账户，结果与 accounts 的顺序一一对应
+//
+// 账户会被拆分为每块最多100个，使用默认并发数并行查询
+func GetMultipleAccounts(ctx context.Context, client *rpc.Client, accounts []solana.PublicKey) ([]AccountResult, error) {
+	return GetMultipleAccountsWithOpts(ctx, client, accounts, DefaultMultipleAccountsConcurrency, nil)
+}
+
+// GetMultipleAccountsWithOpts 与 GetMultipleAccounts 相同，可以指定最大并发数和查询参数
+//
+// 任意一块查询失败时取消其余的查询并返回错误
+func GetMultipleAccountsWithOpts(
+	ctx context.Context,
+	client *rpc.Client,
+	accounts []solana.PublicKey,
+	concurrency int,
+	opts *rpc.GetMultipleAccountsOpts,
+) ([]AccountResult, error) {
+	results := make([]AccountResult, len(accounts))
+	for i, account := range accounts {
+		results[i].PublicKey = account
+	}
+	if len(accounts) == 0 {
+		return results, nil
+	}
+	concurrency = max(concurrency, 1)
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var (
+		wg       sync.WaitGroup
+		errOnce  sync.Once
+		firstErr error
+		sem      = make(chan struct{}, concurrency)
+	)
+	for start := 0; start < len(accounts); start += MaxMultipleAccounts {
+		end := min(start+MaxMultipleAccounts, len(accounts))
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+		}
+		if ctx.Err() != nil {
+			break
+		}
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			out, err := client.GetMultipleAccountsWithOpts(ctx, accounts[start:end], opts)
+			if err == nil && len(out.Value) != end-start {
+				err = fmt.Errorf("节点返回了 %d 个账户，请求了 %d 个", len(out.Value), end-start)
+			}
+			if err != nil {
+				errOnce.Do(func() {
+					firstErr = fmt.Errorf("查询第 %d 到 %d 个账户失败: %w", start, end, err)
+					cancel()
+				})
+				return
+			}
+			for i, account := range out.Value {
+				results[start+i].Account = account
+			}
+		}()
+	}
+	wg.Wait()
+
+	if firstErr != nil {
+		return nil, firstErr
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return results, nil
+}
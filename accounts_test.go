@@ -0,0 +1,57 @@
+package gosolana
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/gagliardetto/solana-go"
+	"github.com/gagliardetto/solana-go/rpc"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_GetMultipleAccounts(t *testing.T) {
+	ctx := context.Background()
+	node := newTestNode(t, 1)
+	// 公钥第一个字节为偶数的账户存在，余额等于第一个字节
+	node.handler = func(w http.ResponseWriter, req map[string]any) bool {
+		keys := req["params"].([]any)[0].([]any)
+		if !assert.LessOrEqual(t, len(keys), MaxMultipleAccounts) {
+			http.Error(w, "too many accounts", http.StatusBadRequest)
+			return true
+		}
+		value := make([]any, len(keys))
+		for i, key := range keys {
+			pub := solana.MustPublicKeyFromBase58(key.(string))
+			if pub[0]%2 == 0 {
+				value[i] = map[string]any{
+					"lamports":   pub[0],
+					"owner":      solana.SystemProgramID.String(),
+					"data":       []string{"", "base64"},
+					"executable": false,
+					"rentEpoch":  0,
+				}
+			}
+		}
+		writeResult(w, req["id"], map[string]any{"context": map[string]any{"slot": 1}, "value": value})
+		return true
+	}
+
+	keys := make([]solana.PublicKey, 250)
+	for i := range keys {
+		keys[i][0] = byte(i)
+		keys[i][1] = 1
+	}
+	results, err := GetMultipleAccounts(ctx, rpc.New(node.URL), keys)
+	require.NoError(t, err)
+	require.Equal(t, int64(3), node.hits.Load())
+	require.Len(t, results, len(keys))
+	for i, res := range results {
+		require.Equal(t, keys[i], res.PublicKey)
+		require.Equal(t, i%2 == 0, res.Exists())
+		if res.Exists() {
+			require.Equal(t, uint64(byte(i)), res.Account.Lamports)
+		}
+	}
+}
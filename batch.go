@@ -0,0 +1,205 @@
+package gosolana
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/gagliardetto/solana-go/rpc"
+	"github.com/gagliardetto/solana-go/rpc/jsonrpc"
+)
+
+var _ rpc.JSONRPCClient = &BatchClient{}
+
+// BatchOptions 自动合并请求的配置
+type BatchOptions struct {
+	Window  time.Duration // 收集请求的时间窗口，默认5毫秒
+	MaxSize int           // 单个批量请求最多包含的请求数，默认100
+}
+
+// BatchClient 将不同 goroutine 在一个时间窗口内发起的独立请求合并为一个 CallBatch 请求，并将每个响应返回给对应的调用方
+//
+// 只有 CallForInto 会被合并，CallWithCallback 和 CallBatch 直接透传给底层客户端
+type BatchClient struct {
+	client  rpc.JSONRPCClient
+	window  time.Duration
+	maxSize int
+
+	lock    sync.Mutex
+	pending []*batchCall
+	timer   *time.Timer
+}
+
+type batchCall struct {
+	ctx     context.Context
+	request *jsonrpc.RPCRequest
+	done    chan batchResult
+}
+
+// batchResult 响应在调用方的 goroutine 中解码，避免调用方取消之后仍然写入 out
+type batchResult struct {
+	raw json.RawMessage
+	err error
+}
+
+// NewBatchClient 创建一个自动合并请求的客户端
+//
+//	client 底层的客户端，可以是 jsonrpc.RPCClient、限流客户端或者 Client 节点池
+func NewBatchClient(client rpc.JSONRPCClient, opts ...BatchOptions) *BatchClient {
+	var opt BatchOptions
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+	if opt.Window <= 0 {
+		opt.Window = 5 * time.Millisecond
+	}
+	if opt.MaxSize <= 0 {
+		opt.MaxSize = 100
+	}
+	return &BatchClient{
+		client:  client,
+		window:  opt.Window,
+		maxSize: opt.MaxSize,
+	}
+}
+
+// CallForInto 实现 rpc.JSONRPCClient，请求会在时间窗口结束或者达到 MaxSize 时发送
+func (b *BatchClient) CallForInto(ctx context.Context, out interface{}, method string, params []interface{}) error {
+	call := &batchCall{
+		ctx: ctx,
+		request: &jsonrpc.RPCRequest{
+			Method:  method,
+			JSONRPC: "2.0",
+		},
+		done: make(chan batchResult, 1),
+	}
+	if params != nil {
+		call.request.Params = params
+	}
+
+	b.lock.Lock()
+	b.pending = append(b.pending, call)
+	switch {
+	case len(b.pending) >= b.maxSize:
+		calls := b.take()
+		go b.send(calls)
+	case len(b.pending) == 1:
+		b.timer = time.AfterFunc(b.window, b.flush)
+	}
+	b.lock.Unlock()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case res := <-call.done:
+		if res.err != nil {
+			return res.err
+		}
+		return json.Unmarshal(res.raw, out)
+	}
+}
+
+func (b *BatchClient) CallWithCallback(
+	ctx context.Context,
+	method string,
+	params []interface{},
+	callback func(*http.Request, *http.Response) error,
+) error {
+	return b.client.CallWithCallback(ctx, method, params, callback)
+}
+
+func (b *BatchClient) CallBatch(ctx context.Context, requests jsonrpc.RPCRequests) (jsonrpc.RPCResponses, error) {
+	return b.client.CallBatch(ctx, requests)
+}
+
+// Close 发送还在等待中的请求并关闭底层客户端
+func (b *BatchClient) Close() error {
+	b.flush()
+	if c, ok := b.client.(io.Closer); ok {
+		return c.Close()
+	}
+	return nil
+}
+
+// take 取出所有等待中的请求，调用时必须持有锁
+func (b *BatchClient) take() []*batchCall {
+	if b.timer != nil {
+		b.timer.Stop()
+		b.timer = nil
+	}
+	calls := b.pending
+	b.pending = nil
+	return calls
+}
+
+func (b *BatchClient) flush() {
+	b.lock.Lock()
+	calls := b.take()
+	b.lock.Unlock()
+	b.send(calls)
+}
+
+// send 发送一批请求，单个调用方取消时其他调用方仍然需要响应，因此不直接使用调用方的上下文，
+// 所有调用方都取消之后才取消请求，避免节点没有响应时一直占用 goroutine
+func (b *BatchClient) send(calls []*batchCall) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	var waiting atomic.Int64
+	waiting.Store(int64(len(calls)))
+	for _, call := range calls {
+		stop := context.AfterFunc(call.ctx, func() {
+			if waiting.Add(-1) == 0 {
+				cancel()
+			}
+		})
+		defer stop()
+	}
+
+	switch len(calls) {
+	case 0:
+		return
+	case 1:
+		var res batchResult
+		res.err = b.client.CallForInto(ctx, &res.raw, calls[0].request.Method, calls[0].params())
+		calls[0].done <- res
+		return
+	}
+
+	requests := make(jsonrpc.RPCRequests, len(calls))
+	for i, call := range calls {
+		call.request.ID = i
+		requests[i] = call.request
+	}
+	responses, err := b.client.CallBatch(ctx, requests)
+	if err != nil {
+		for _, call := range calls {
+			call.done <- batchResult{err: err}
+		}
+		return
+	}
+
+	byID := responses.AsMap()
+	for i, call := range calls {
+		resp, ok := byID[i]
+		switch {
+		case !ok:
+			call.done <- batchResult{err: fmt.Errorf("批量请求中缺少 %s() 的响应", call.request.Method)}
+		case resp.Error != nil:
+			call.done <- batchResult{err: resp.Error}
+		case resp.Result == nil:
+			call.done <- batchResult{raw: json.RawMessage("null")}
+		default:
+			call.done <- batchResult{raw: resp.Result}
+		}
+	}
+}
+
+func (c *batchCall) params() []interface{} {
+	params, _ := c.request.Params.([]interface{})
+	return params
+}
@@ -0,0 +1,138 @@
+package gosolana
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gagliardetto/solana-go"
+	"github.com/gagliardetto/solana-go/rpc"
+	"github.com/gagliardetto/solana-go/rpc/jsonrpc"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_BatchClient(t *testing.T) {
+	ctx := context.Background()
+	node := newTestNode(t, 3)
+	client := rpc.NewWithCustomRPCClient(NewBatchClient(
+		jsonrpc.NewClient(node.URL),
+		BatchOptions{Window: 50 * time.Millisecond, MaxSize: 8},
+	))
+
+	var wg sync.WaitGroup
+	for i := 0; i < 16; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			out, err := client.GetBalance(ctx, solana.NewWallet().PublicKey(), rpc.CommitmentProcessed)
+			if assert.NoError(t, err) {
+				assert.Equal(t, uint64(3), out.Value)
+			}
+		}()
+	}
+	wg.Wait()
+	require.Equal(t, int64(2), node.hits.Load(), "16个请求应该被合并为2个批量请求")
+}
+
+// batchFunc 只实现 CallBatch 的底层客户端，用于构造节点的批量响应
+type batchFunc func(ctx context.Context, requests jsonrpc.RPCRequests) (jsonrpc.RPCResponses, error)
+
+func (f batchFunc) CallForInto(ctx context.Context, out interface{}, method string, params []interface{}) error {
+	return errors.New("unexpected CallForInto")
+}
+
+func (f batchFunc) CallWithCallback(ctx context.Context, method string, params []interface{}, callback func(*http.Request, *http.Response) error) error {
+	return errors.New("unexpected CallWithCallback")
+}
+
+func (f batchFunc) CallBatch(ctx context.Context, requests jsonrpc.RPCRequests) (jsonrpc.RPCResponses, error) {
+	return f(ctx, requests)
+}
+
+// callBatch 并发发起请求，MaxSize 等于请求数，所有请求会合并为一个批量请求
+func callBatch(ctx context.Context, client *BatchClient, methods ...string) []error {
+	errs := make([]error, len(methods))
+	var wg sync.WaitGroup
+	for i, method := range methods {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			var out json.RawMessage
+			errs[i] = client.CallForInto(ctx, &out, method, nil)
+		}()
+	}
+	wg.Wait()
+	return errs
+}
+
+func Test_BatchClientPerCallErrors(t *testing.T) {
+	ctx := context.Background()
+	client := NewBatchClient(batchFunc(func(ctx context.Context, requests jsonrpc.RPCRequests) (jsonrpc.RPCResponses, error) {
+		var responses jsonrpc.RPCResponses
+		for _, req := range requests {
+			// 和 jsonrpc 客户端解码的结果一致，id 为 json.Number
+			resp := &jsonrpc.RPCResponse{JSONRPC: "2.0", ID: json.Number(fmt.Sprint(req.ID))}
+			switch req.Method {
+			case "getSlot":
+				resp.Result = json.RawMessage("1")
+			case "getBalance":
+				resp.Error = &jsonrpc.RPCError{Code: -32602, Message: "invalid params"}
+			case "getHealth":
+				// 节点漏掉了这个请求的响应
+				continue
+			}
+			responses = append(responses, resp)
+		}
+		return responses, nil
+	}), BatchOptions{Window: time.Second, MaxSize: 3})
+
+	errs := callBatch(ctx, client, "getSlot", "getBalance", "getHealth")
+	require.NoError(t, errs[0])
+	var rpcErr *jsonrpc.RPCError
+	require.ErrorAs(t, errs[1], &rpcErr, "单个请求的错误只返回给对应的调用方")
+	require.Equal(t, -32602, rpcErr.Code)
+	require.ErrorContains(t, errs[2], "缺少 getHealth() 的响应")
+}
+
+func Test_BatchClientCancel(t *testing.T) {
+	cancelled := make(chan struct{})
+	client := NewBatchClient(batchFunc(func(ctx context.Context, requests jsonrpc.RPCRequests) (jsonrpc.RPCResponses, error) {
+		// 节点一直没有响应
+		<-ctx.Done()
+		close(cancelled)
+		return nil, ctx.Err()
+	}), BatchOptions{Window: time.Second, MaxSize: 2})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	short, cancelShort := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancelShort()
+	var wg sync.WaitGroup
+	wg.Add(2)
+	var errs [2]error
+	go func() { defer wg.Done(); errs[0] = client.CallForInto(short, new(json.RawMessage), "getSlot", nil) }()
+	go func() { defer wg.Done(); errs[1] = client.CallForInto(ctx, new(json.RawMessage), "getSlot", nil) }()
+
+	// 还有调用方在等待时不应该取消请求
+	time.Sleep(100 * time.Millisecond)
+	select {
+	case <-cancelled:
+		t.Fatal("还有调用方在等待时不应该取消请求")
+	default:
+	}
+
+	cancel()
+	select {
+	case <-cancelled:
+	case <-time.After(time.Second):
+		t.Fatal("所有调用方都取消之后应该取消请求")
+	}
+	wg.Wait()
+	require.ErrorIs(t, errs[0], context.DeadlineExceeded)
+	require.ErrorIs(t, errs[1], context.Canceled)
+}
@@ -0,0 +1,263 @@
+package gosolana
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/go-enols/gosolana/log"
+)
+
+// ErrCircuitOpen 所有可用的RPC节点都处于熔断状态
+var ErrCircuitOpen = errors.New("所有RPC节点均已熔断")
+
+// BreakerState 熔断器状态
+type BreakerState int32
+
+const (
+	BreakerClosed   BreakerState = iota // 正常放行请求
+	BreakerOpen                         // 熔断中，请求直接跳过该节点
+	BreakerHalfOpen                     // 熔断超时后放行少量探测请求
+)
+
+func (s BreakerState) String() string {
+	switch s {
+	case BreakerClosed:
+		return "closed"
+	case BreakerOpen:
+		return "open"
+	case BreakerHalfOpen:
+		return "half-open"
+	default:
+		return "unknown"
+	}
+}
+
+// BreakerConfig 节点熔断器配置
+//
+// 连续失败次数达到 ConsecutiveFailures，或者滚动窗口内的错误率达到 ErrorRate 时熔断，
+// 熔断 OpenTimeout 之后进入半开状态，探测请求成功则恢复，失败则重新熔断。
+// 只有可以重试的错误(见 RetryPolicy.Retryable)才会被计为失败
+type BreakerConfig struct {
+	ConsecutiveFailures int           // 默认5
+	ErrorRate           float64       // 默认0.5
+	MinRequests         int           // 窗口内请求数少于该值时不计算错误率，默认20
+	Window              time.Duration // 错误率的滚动窗口，默认30秒
+	OpenTimeout         time.Duration // 默认10秒
+	HalfOpenRequests    int           // 半开状态下同时允许的探测请求数，默认1
+
+	// OnStateChange 熔断器状态变化时的回调，可用于告警
+	OnStateChange func(endpoint string, from, to BreakerState)
+}
+
+// WithCircuitBreaker 为池中的每个节点开启熔断器
+func WithCircuitBreaker(config BreakerConfig) ClientOption {
+	return func(ctx context.Context, client *Client) {
+		if config.ConsecutiveFailures <= 0 {
+			config.ConsecutiveFailures = 5
+		}
+		if config.ErrorRate <= 0 {
+			config.ErrorRate = 0.5
+		}
+		if config.MinRequests <= 0 {
+			config.MinRequests = 20
+		}
+		if config.Window <= 0 {
+			config.Window = 30 * time.Second
+		}
+		if config.OpenTimeout <= 0 {
+			config.OpenTimeout = 10 * time.Second
+		}
+		if config.HalfOpenRequests <= 0 {
+			config.HalfOpenRequests = 1
+		}
+		client.breaker = &config
+	}
+}
+
+const breakerBuckets = 10
+
+type breakerBucket struct {
+	start    time.Time
+	success  int
+	failures int
+}
+
+// breaker 单个节点的熔断器，nil 表示未开启熔断
+type breaker struct {
+	lock   sync.Mutex
+	name   string
+	config BreakerConfig
+	now    func() time.Time
+
+	state       BreakerState
+	openedAt    time.Time
+	consecutive int
+	probes      int
+	buckets     [breakerBuckets]breakerBucket
+}
+
+func newBreaker(name string, config BreakerConfig) *breaker {
+	return &breaker{name: name, config: config, now: time.Now}
+}
+
+// State 返回熔断器当前的状态
+func (b *breaker) State() BreakerState {
+	if b == nil {
+		return BreakerClosed
+	}
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	return b.state
+}
+
+// ready 是否可能放行请求，不占用半开状态的探测名额
+func (b *breaker) ready() bool {
+	if b == nil {
+		return true
+	}
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	switch b.state {
+	case BreakerOpen:
+		return !b.now().Before(b.openedAt.Add(b.config.OpenTimeout))
+	case BreakerHalfOpen:
+		return b.probes < b.config.HalfOpenRequests
+	default:
+		return true
+	}
+}
+
+// allow 判断是否放行一个请求，放行之后必须调用 record 或者 abandon
+func (b *breaker) allow() bool {
+	if b == nil {
+		return true
+	}
+	b.lock.Lock()
+	var from, to BreakerState
+	changed := false
+	defer func() {
+		b.lock.Unlock()
+		if changed {
+			b.notify(from, to)
+		}
+	}()
+
+	switch b.state {
+	case BreakerOpen:
+		if b.now().Before(b.openedAt.Add(b.config.OpenTimeout)) {
+			return false
+		}
+		from, to, changed = b.state, BreakerHalfOpen, true
+		b.state = BreakerHalfOpen
+		b.probes = 1
+		return true
+	case BreakerHalfOpen:
+		if b.probes >= b.config.HalfOpenRequests {
+			return false
+		}
+		b.probes++
+		return true
+	default:
+		return true
+	}
+}
+
+// abandon 放行的请求因为调用方取消而没有结果，释放探测名额
+func (b *breaker) abandon() {
+	if b == nil {
+		return
+	}
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	if b.state == BreakerHalfOpen && b.probes > 0 {
+		b.probes--
+	}
+}
+
+// record 记录一次请求的结果
+func (b *breaker) record(success bool) {
+	if b == nil {
+		return
+	}
+	b.lock.Lock()
+	from := b.state
+	to := b.transition(success)
+	b.lock.Unlock()
+
+	if from != to {
+		b.notify(from, to)
+	}
+}
+
+func (b *breaker) transition(success bool) BreakerState {
+	now := b.now()
+	bucket := b.bucket(now)
+	if success {
+		bucket.success++
+		b.consecutive = 0
+	} else {
+		bucket.failures++
+		b.consecutive++
+	}
+
+	switch b.state {
+	case BreakerHalfOpen:
+		b.probes = max(b.probes-1, 0)
+		if success {
+			b.reset()
+		} else {
+			b.trip(now)
+		}
+	case BreakerClosed:
+		if !success && (b.consecutive >= b.config.ConsecutiveFailures || b.errorRateExceeded(now)) {
+			b.trip(now)
+		}
+	}
+	return b.state
+}
+
+// bucket 返回当前时间所在的统计桶，过期的桶会被清空
+func (b *breaker) bucket(now time.Time) *breakerBucket {
+	width := b.config.Window / breakerBuckets
+	start := now.Truncate(width)
+	bucket := &b.buckets[(start.UnixNano()/int64(width))%breakerBuckets]
+	if !bucket.start.Equal(start) {
+		*bucket = breakerBucket{start: start}
+	}
+	return bucket
+}
+
+func (b *breaker) errorRateExceeded(now time.Time) bool {
+	var success, failures int
+	for _, bucket := range b.buckets {
+		if now.Sub(bucket.start) < b.config.Window {
+			success += bucket.success
+			failures += bucket.failures
+		}
+	}
+	total := success + failures
+	return total >= b.config.MinRequests && float64(failures)/float64(total) >= b.config.ErrorRate
+}
+
+func (b *breaker) trip(now time.Time) {
+	b.state = BreakerOpen
+	b.openedAt = now
+	b.probes = 0
+}
+
+func (b *breaker) reset() {
+	b.state = BreakerClosed
+	b.consecutive = 0
+	b.probes = 0
+	b.buckets = [breakerBuckets]breakerBucket{}
+}
+
+func (b *breaker) notify(from, to BreakerState) {
+	log.Warn("RPC节点熔断器状态变化", "endpoint", b.name, "from", from, "to", to)
+	if b.config.OnStateChange != nil {
+		b.config.OnStateChange(b.name, from, to)
+	}
+}
@@ -2,44 +2,417 @@ package gosolana
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"sync"
+	"sync/atomic"
 
 	"github.com/gagliardetto/solana-go/rpc"
 	"github.com/gagliardetto/solana-go/rpc/jsonrpc"
-	"github.com/go-enols/go-log"
+	"github.com/go-enols/gosolana/log"
 )
 
-type ClientOption func(ctx context.Context, client *client)
+// ErrNoEndpoint 池中没有可用的RPC节点
+var ErrNoEndpoint = errors.New("没有可用的RPC节点")
 
-// 从原先的Wallet中改造过来,进行负载均衡
-type client struct {
-	ctx context.Context // 父级上下文
+var _ rpc.JSONRPCClient = &Client{}
 
-	rpc []*rpc.Client
+type ClientOption func(ctx context.Context, client *Client)
+
+// Client 多节点的RPC客户端池，从原先的Wallet中改造过来,进行负载均衡
+//
+// Client 内嵌了 *rpc.Client，因此可以像使用 *rpc.Client 一样直接调用 GetBalance、GetAccountInfo 等方法，
+// 每一个请求都会按照负载均衡策略分发到池中的某一个节点
+type Client struct {
+	*rpc.Client
+
+	ctx    context.Context // 父级上下文
+	cancel context.CancelFunc
+
+	endpoints []*Endpoint
+	balancer  Balancer
+	retry     RetryPolicy
+	health    *HealthCheck
+	breaker   *BreakerConfig
+	hedge     *HedgePolicy
+	latency   *latencyTracker
+
+	groups       map[string][]*Endpoint // 节点分组
+	methodGroups map[string][]string    // 方法分组
+	routes       map[string]string      // 方法或方法分组 -> 节点分组
+	routeOrder   []string               // 路由规则的添加顺序，决定方法分组的优先级
+	defaultRoute string
 }
 
-// TODO:还未完成先搁置，后面需要转移到这里，完成负载均衡
-func NewRPCClient(ctx context.Context, netWork rpc.Cluster, opt ...ClientOption) *client {
-	c := new(client)
-	c.ctx = ctx
+// NewRPCClient 创建一个RPC客户端池
+//
+//	netWork 没有通过选项添加任何节点时使用的默认网络
+func NewRPCClient(ctx context.Context, netWork rpc.Cluster, opt ...ClientOption) *Client {
+	c := new(Client)
+	c.ctx, c.cancel = context.WithCancel(ctx)
+	c.retry = DefaultRetryPolicy()
+	c.methodGroups = defaultMethodGroups()
+	c.routes = map[string]string{}
 
 	for _, fn := range opt {
 		fn(ctx, c)
 	}
-	if len(c.rpc) == 0 { // 如果没有rpc就填入默认节点
-		defaultRPC(netWork) // 创建默认的RPC节点,如果用户自定义了就直接覆盖
+	if len(c.endpoints) == 0 { // 如果没有rpc就填入默认节点
+		defaultRPC(netWork)(ctx, c)
+	}
+	if c.balancer == nil {
+		c.balancer = RoundRobin()
+	}
+	c.buildGroups()
+	if c.breaker != nil {
+		for _, ep := range c.endpoints {
+			ep.breaker = newBreaker(ep.Name, *c.breaker)
+		}
+	}
+	c.Client = rpc.NewWithCustomRPCClient(c)
+	if c.health != nil {
+		go c.healthLoop()
 	}
 
 	return c
 }
 
+// Endpoints 返回池中的所有节点
+func (c *Client) Endpoints() []*Endpoint {
+	return c.endpoints
+}
+
+// CallForInto 实现 rpc.JSONRPCClient
+func (c *Client) CallForInto(ctx context.Context, out interface{}, method string, params []interface{}) error {
+	endpoints, err := c.route(method)
+	if err != nil {
+		return err
+	}
+	if c.hedge != nil && len(endpoints) > 1 && c.hedge.applies(method) {
+		return c.hedged(ctx, endpoints, out, method, params)
+	}
+	return c.do(ctx, endpoints, func(ep *Endpoint) error {
+		return ep.rpc.RPCCallForInto(ctx, out, method, params)
+	})
+}
+
+// CallWithCallback 实现 rpc.JSONRPCClient
+func (c *Client) CallWithCallback(
+	ctx context.Context,
+	method string,
+	params []interface{},
+	callback func(*http.Request, *http.Response) error,
+) error {
+	endpoints, err := c.route(method)
+	if err != nil {
+		return err
+	}
+	return c.do(ctx, endpoints, func(ep *Endpoint) error {
+		return ep.rpc.RPCCallWithCallback(ctx, method, params, callback)
+	})
+}
+
+// CallBatch 实现 rpc.JSONRPCClient，整个批次会发送到同一个节点
+func (c *Client) CallBatch(ctx context.Context, requests jsonrpc.RPCRequests) (out jsonrpc.RPCResponses, err error) {
+	endpoints, err := c.routeBatch(requests)
+	if err != nil {
+		return nil, err
+	}
+	err = c.do(ctx, endpoints, func(ep *Endpoint) error {
+		out, err = ep.rpc.RPCCallBatch(ctx, requests)
+		return err
+	})
+	return
+}
+
+// Close 停止健康检查并关闭池中所有的节点
+func (c *Client) Close() error {
+	c.cancel()
+	var errs []error
+	for _, ep := range c.endpoints {
+		if err := ep.rpc.Close(); err != nil {
+			errs = append(errs, fmt.Errorf("关闭节点 %s 失败: %w", ep.Name, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// do 从 endpoints 中选出一个节点并在其上执行请求，失败时按照重试策略换到下一个节点重试
+func (c *Client) do(ctx context.Context, endpoints []*Endpoint, call func(ep *Endpoint) error) error {
+	return c.attempt(ctx, endpoints, &triedSet{}, call)
+}
+
+// attempt 与 do 相同，tried 记录已经尝试过的节点，可以在多个并发的请求之间共享
+func (c *Client) attempt(ctx context.Context, endpoints []*Endpoint, tried *triedSet, call func(ep *Endpoint) error) error {
+	if len(endpoints) == 0 {
+		return ErrNoEndpoint
+	}
+
+	var lastErr error
+	for attempt := 0; attempt < max(c.retry.MaxAttempts, 1); attempt++ {
+		if attempt > 0 {
+			if err := sleepContext(ctx, c.retry.backoff(attempt)); err != nil {
+				return lastErr
+			}
+		}
+		ep := c.pick(endpoints, tried)
+		if ep == nil {
+			break
+		}
+
+		err := c.invoke(ep, call)
+		if ctx.Err() != nil {
+			ep.breaker.abandon()
+			return err
+		}
+		retryable := c.retry.Retryable(err)
+		ep.breaker.record(!retryable)
+		if err == nil {
+			return nil
+		}
+		lastErr = err
+		if !retryable {
+			return err
+		}
+		log.Warn("RPC节点请求失败，准备重试", "endpoint", ep.Name, "error", err)
+	}
+	if lastErr == nil {
+		return ErrCircuitOpen
+	}
+	return lastErr
+}
+
+// triedSet 一次请求中已经尝试过的节点
+type triedSet struct {
+	lock sync.Mutex
+	m    map[*Endpoint]bool
+}
+
+// pick 从 endpoints 中健康、未熔断并且还没有尝试过的节点中选出一个，并将其记录到 tried 中
+//
+// 所有健康节点都尝试过之后重新从健康节点中选择，没有任何健康节点时退回到所有未熔断的节点，
+// 全部节点都已熔断时返回 nil
+func (c *Client) pick(endpoints []*Endpoint, tried *triedSet) *Endpoint {
+	tried.lock.Lock()
+	defer tried.lock.Unlock()
+	if tried.m == nil {
+		tried.m = make(map[*Endpoint]bool, len(endpoints))
+	}
+
+	var ready, healthy []*Endpoint
+	for _, ep := range endpoints {
+		if !ep.breaker.ready() {
+			continue
+		}
+		ready = append(ready, ep)
+		if ep.Healthy() {
+			healthy = append(healthy, ep)
+		}
+	}
+	if len(healthy) == 0 {
+		healthy = ready
+	}
+
+	candidates := make([]*Endpoint, 0, len(healthy))
+	for _, ep := range healthy {
+		if !tried.m[ep] {
+			candidates = append(candidates, ep)
+		}
+	}
+	if len(candidates) == 0 {
+		candidates = healthy
+	}
+
+	for len(candidates) > 0 {
+		ep := c.balancer.Pick(candidates)
+		if ep == nil {
+			return nil
+		}
+		if ep.breaker.allow() {
+			tried.m[ep] = true
+			return ep
+		}
+		// 半开状态的探测名额已经被其他请求占用
+		candidates = slices.DeleteFunc(slices.Clone(candidates), func(e *Endpoint) bool { return e == ep })
+	}
+	return nil
+}
+
+// invoke 在指定节点上执行一次请求
+func (c *Client) invoke(ep *Endpoint, call func(ep *Endpoint) error) error {
+	ep.inFlight.Add(1)
+	defer ep.inFlight.Add(-1)
+	return call(ep)
+}
+
+func (c *Client) addEndpoint(client *rpc.Client, endpoint string, opts ...EndpointOption) {
+	ep := &Endpoint{
+		Name:   endpointHost(endpoint),
+		Weight: 1,
+		rpc:    client,
+	}
+	ep.healthy.Store(true)
+	for _, fn := range opts {
+		fn(ep)
+	}
+	if ep.Name == "" {
+		ep.Name = fmt.Sprintf("rpc-%d", len(c.endpoints))
+	}
+	if ep.Weight <= 0 {
+		ep.Weight = 1
+	}
+	c.endpoints = append(c.endpoints, ep)
+}
+
+// Endpoint 池中的一个RPC节点
+type Endpoint struct {
+	Name   string   // 节点名称，默认为节点的主机名，不包含可能带有 api-key 的路径和查询参数
+	Weight int      // 加权策略下的权重，默认为1
+	Groups []string // 节点所属的分组，见 WithRoute
+
+	rpc      *rpc.Client
+	inFlight atomic.Int64
+	healthy  atomic.Bool
+	slot     atomic.Uint64
+	breaker  *breaker
+}
+
+// RPC 返回节点底层的 *rpc.Client
+func (e *Endpoint) RPC() *rpc.Client {
+	return e.rpc
+}
+
+// InFlight 返回节点上正在进行中的请求数量
+func (e *Endpoint) InFlight() int64 {
+	return e.inFlight.Load()
+}
+
+// Healthy 节点是否在轮询中，未开启健康检查时总是为 true
+func (e *Endpoint) Healthy() bool {
+	return e.healthy.Load()
+}
+
+// BreakerState 节点熔断器的状态，未开启熔断时总是为 BreakerClosed
+func (e *Endpoint) BreakerState() BreakerState {
+	return e.breaker.State()
+}
+
+// Slot 最近一次健康检查时节点返回的slot
+func (e *Endpoint) Slot() uint64 {
+	return e.slot.Load()
+}
+
+type EndpointOption func(ep *Endpoint)
+
+// WithName 设置节点名称
+func WithName(name string) EndpointOption {
+	return func(ep *Endpoint) {
+		ep.Name = name
+	}
+}
+
+// WithWeight 设置节点在加权策略下的权重
+func WithWeight(weight int) EndpointOption {
+	return func(ep *Endpoint) {
+		ep.Weight = weight
+	}
+}
+
+// Balancer 负载均衡策略，从候选节点中选出一个节点
+type Balancer interface {
+	Pick(endpoints []*Endpoint) *Endpoint
+}
+
+// RoundRobin 轮询策略
+func RoundRobin() Balancer {
+	return &roundRobin{}
+}
+
+type roundRobin struct {
+	next atomic.Uint64
+}
+
+func (b *roundRobin) Pick(endpoints []*Endpoint) *Endpoint {
+	if len(endpoints) == 0 {
+		return nil
+	}
+	n := b.next.Add(1) - 1
+	return endpoints[n%uint64(len(endpoints))]
+}
+
+// LeastInFlight 最少进行中请求策略，进行中请求数相同的节点之间轮询
+func LeastInFlight() Balancer {
+	return &leastInFlight{}
+}
+
+type leastInFlight struct {
+	next atomic.Uint64
+}
+
+func (b *leastInFlight) Pick(endpoints []*Endpoint) *Endpoint {
+	if len(endpoints) == 0 {
+		return nil
+	}
+	offset := int(b.next.Add(1) - 1)
+	var best *Endpoint
+	for i := range endpoints {
+		ep := endpoints[(offset+i)%len(endpoints)]
+		if best == nil || ep.InFlight() < best.InFlight() {
+			best = ep
+		}
+	}
+	return best
+}
+
+// Weighted 平滑加权轮询策略，按照节点的 Weight 分配请求
+func Weighted() Balancer {
+	return &weighted{current: map[*Endpoint]int{}}
+}
+
+type weighted struct {
+	lock    sync.Mutex
+	current map[*Endpoint]int
+}
+
+func (b *weighted) Pick(endpoints []*Endpoint) *Endpoint {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	var (
+		best  *Endpoint
+		total int
+	)
+	for _, ep := range endpoints {
+		b.current[ep] += ep.Weight
+		total += ep.Weight
+		if best == nil || b.current[ep] > b.current[best] {
+			best = ep
+		}
+	}
+	if best != nil {
+		b.current[best] -= total
+	}
+	return best
+}
+
+// WithBalancer 设置负载均衡策略，默认为轮询
+func WithBalancer(balancer Balancer) ClientOption {
+	return func(ctx context.Context, client *Client) {
+		client.balancer = balancer
+	}
+}
+
 // 默认的RPC节点
 func defaultRPC(netWork rpc.Cluster) ClientOption {
-	return func(ctx context.Context, client *client) {
-		client.rpc = []*rpc.Client{
+	return func(ctx context.Context, client *Client) {
+		client.addEndpoint(
 			rpc.NewWithCustomRPCClient(
-				rpc.NewWithRateLimit(netWork.RPC, 1), // 设置请求限制，每秒1条
+				NewWithRateLimit(netWork.RPC, 1, nil), // 设置请求限制，每秒1条，和指向同一节点的客户端共享限流器
 			),
-		}
+			netWork.RPC,
+		)
 	}
 }
 
@@ -52,35 +425,44 @@ func WithRPCProxy(endpoint, proxy string, rps ...int) ClientOption {
 	if len(rps) > 0 {
 		rp = rps[0]
 	}
+	return WithRPCProxyOpts(endpoint, proxy, rp)
+}
+
+// WithRPCProxyOpts 与 WithRPCProxy 相同，可以同时设置节点的名称、权重、分组等配置
+//
+//	rps 速度限制每秒请求多少次，0表示不限制
+func WithRPCProxyOpts(endpoint, proxy string, rps int, opts ...EndpointOption) ClientOption {
 	httpClient, err := NewProxyHttpClient(proxy)
 	if err != nil {
-		log.Error("你提供了一个无效的代理")
-		return func(ctx context.Context, client *client) {
+		log.Error("你提供了一个无效的代理", "error", err)
+		return func(ctx context.Context, client *Client) {
 
 		}
 	}
 
-	return func(ctx context.Context, client *client) {
-		if rp != 0 { // 如果设置了速度限制
-			client.rpc = append(client.rpc, rpc.NewWithCustomRPCClient(
-				NewWithRateLimit(endpoint, rp, &jsonrpc.RPCClientOpts{
+	return func(ctx context.Context, client *Client) {
+		if rps != 0 { // 如果设置了速度限制
+			client.addEndpoint(rpc.NewWithCustomRPCClient(
+				NewWithRateLimit(endpoint, rps, &jsonrpc.RPCClientOpts{
 					HTTPClient: httpClient,
 				}),
-			))
+			), endpoint, opts...)
 		} else { // 不进行速度限制
-			client.rpc = append(client.rpc,
+			client.addEndpoint(
 				rpc.NewWithCustomRPCClient(
 					jsonrpc.NewClientWithOpts(endpoint, &jsonrpc.RPCClientOpts{
 						HTTPClient: httpClient,
 					})),
+				endpoint,
+				opts...,
 			)
 		}
 	}
 }
 
 // 设置一个已有的rpc节点
-func WithRPCClient(rpc *rpc.Client) ClientOption {
-	return func(ctx context.Context, client *client) {
-		client.rpc = append(client.rpc, rpc)
+func WithRPCClient(rpc *rpc.Client, opts ...EndpointOption) ClientOption {
+	return func(ctx context.Context, client *Client) {
+		client.addEndpoint(rpc, "", opts...)
 	}
 }
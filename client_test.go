@@ -0,0 +1,472 @@
+package gosolana
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gagliardetto/solana-go"
+	"github.com/gagliardetto/solana-go/rpc"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testNode 一个本地的JSON-RPC节点，getBalance 返回节点自身的编号，getSlot 返回 slot
+type testNode struct {
+	*httptest.Server
+	id      uint64
+	slot    atomic.Uint64
+	hits    atomic.Int64
+	handler func(w http.ResponseWriter, req map[string]any) bool // 返回 true 表示已经自行处理
+}
+
+func newTestNode(t *testing.T, id uint64) *testNode {
+	n := &testNode{id: id}
+	n.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n.hits.Add(1)
+		var body json.RawMessage
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if body[0] != '[' {
+			var req map[string]any
+			json.Unmarshal(body, &req)
+			n.serve(w, req)
+			return
+		}
+
+		// 批量请求逐个处理后合并结果
+		var reqs []map[string]any
+		json.Unmarshal(body, &reqs)
+		results := make([]json.RawMessage, 0, len(reqs))
+		for _, req := range reqs {
+			rec := httptest.NewRecorder()
+			n.serve(rec, req)
+			results = append(results, rec.Body.Bytes())
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(results)
+	}))
+	t.Cleanup(n.Close)
+	return n
+}
+
+func (n *testNode) serve(w http.ResponseWriter, req map[string]any) {
+	if n.handler != nil && n.handler(w, req) {
+		return
+	}
+	switch req["method"] {
+	case "getHealth":
+		writeResult(w, req["id"], "ok")
+	case "getSlot":
+		writeResult(w, req["id"], n.slot.Load())
+	default:
+		writeResult(w, req["id"], map[string]any{
+			"context": map[string]any{"slot": 1},
+			"value":   n.id,
+		})
+	}
+}
+
+func writeResult(w http.ResponseWriter, id any, result any) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]any{
+		"jsonrpc": "2.0",
+		"id":      id,
+		"result":  result,
+	})
+}
+
+func testPool(ctx context.Context, nodes []*testNode, opts ...ClientOption) *Client {
+	var all []ClientOption
+	for _, n := range nodes {
+		all = append(all, WithRPCClient(rpc.New(n.URL), WithName(fmt.Sprint(n.id))))
+	}
+	return NewRPCClient(ctx, rpc.LocalNet, append(all, opts...)...)
+}
+
+func Test_ClientRoundRobin(t *testing.T) {
+	ctx := context.Background()
+	nodes := []*testNode{newTestNode(t, 1), newTestNode(t, 2), newTestNode(t, 3)}
+	pool := testPool(ctx, nodes)
+	defer pool.Close()
+
+	for i := 0; i < 9; i++ {
+		out, err := pool.GetBalance(ctx, solana.SystemProgramID, rpc.CommitmentProcessed)
+		require.NoError(t, err)
+		require.Equal(t, uint64(i%3+1), out.Value)
+	}
+	for _, n := range nodes {
+		require.Equal(t, int64(3), n.hits.Load())
+	}
+}
+
+func Test_ClientWeighted(t *testing.T) {
+	ctx := context.Background()
+	heavy, light := newTestNode(t, 1), newTestNode(t, 2)
+	pool := NewRPCClient(ctx, rpc.LocalNet,
+		WithRPCClient(rpc.New(heavy.URL), WithWeight(3)),
+		WithRPCClient(rpc.New(light.URL)),
+		WithBalancer(Weighted()),
+	)
+	defer pool.Close()
+
+	for i := 0; i < 40; i++ {
+		_, err := pool.GetBalance(ctx, solana.SystemProgramID, rpc.CommitmentProcessed)
+		require.NoError(t, err)
+	}
+	require.Equal(t, int64(30), heavy.hits.Load())
+	require.Equal(t, int64(10), light.hits.Load())
+}
+
+func Test_ClientLeastInFlight(t *testing.T) {
+	ctx := context.Background()
+	slow, fast := newTestNode(t, 1), newTestNode(t, 2)
+	release := make(chan struct{})
+	entered := make(chan struct{})
+	slow.handler = func(w http.ResponseWriter, req map[string]any) bool {
+		entered <- struct{}{}
+		<-release
+		return false
+	}
+	pool := testPool(ctx, []*testNode{slow, fast}, WithBalancer(LeastInFlight()))
+	defer pool.Close()
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		_, err := pool.GetBalance(ctx, solana.SystemProgramID, rpc.CommitmentProcessed)
+		assert.NoError(t, err)
+	}()
+	<-entered
+
+	// 慢节点上有一个进行中的请求，后续请求都应该落到快节点上
+	for i := 0; i < 5; i++ {
+		out, err := pool.GetBalance(ctx, solana.SystemProgramID, rpc.CommitmentProcessed)
+		require.NoError(t, err)
+		require.Equal(t, uint64(2), out.Value)
+	}
+	close(release)
+	wg.Wait()
+	require.Equal(t, int64(1), slow.hits.Load())
+}
+
+func Test_ClientDefaultEndpoint(t *testing.T) {
+	pool := NewRPCClient(context.Background(), rpc.DevNet)
+	require.Len(t, pool.Endpoints(), 1)
+	require.Equal(t, "api.devnet.solana.com", pool.Endpoints()[0].Name)
+	// 默认节点使用共享的限流器，和其他客户端一起遵守节点的限流
+	_, shared := LookupCreditLimiter(rpc.DevNet.RPC)
+	require.True(t, shared)
+
+	// 节点名称会出现在日志中，不能包含查询参数中的 api-key
+	pool = NewRPCClient(context.Background(), rpc.DevNet, WithRPCProxy("https://mainnet.helius-rpc.com/?api-key=secret", "http://127.0.0.1:1"))
+	require.Len(t, pool.Endpoints(), 1)
+	require.Equal(t, "mainnet.helius-rpc.com", pool.Endpoints()[0].Name)
+}
+
+func Test_ClientFailover(t *testing.T) {
+	ctx := context.Background()
+	for _, status := range []int{http.StatusBadGateway, http.StatusTooManyRequests} {
+		failing, healthy := newTestNode(t, 1), newTestNode(t, 2)
+		failing.handler = func(w http.ResponseWriter, req map[string]any) bool {
+			w.WriteHeader(status)
+			return true
+		}
+		pool := testPool(ctx, []*testNode{failing, healthy})
+
+		out, err := pool.GetBalance(ctx, solana.SystemProgramID, rpc.CommitmentProcessed)
+		require.NoError(t, err)
+		require.Equal(t, uint64(2), out.Value)
+		require.Equal(t, int64(1), failing.hits.Load())
+		pool.Close()
+	}
+}
+
+func Test_ClientRetryableCodes(t *testing.T) {
+	ctx := context.Background()
+	behind, healthy := newTestNode(t, 1), newTestNode(t, 2)
+	behind.handler = func(w http.ResponseWriter, req map[string]any) bool {
+		json.NewEncoder(w).Encode(map[string]any{
+			"jsonrpc": "2.0",
+			"id":      req["id"],
+			"error":   map[string]any{"code": -32005, "message": "Node is behind"},
+		})
+		return true
+	}
+
+	pool := testPool(ctx, []*testNode{behind, healthy}, WithRetryPolicy(RetryPolicy{MaxAttempts: 2}))
+	_, err := pool.GetBalance(ctx, solana.SystemProgramID, rpc.CommitmentProcessed)
+	require.Error(t, err, "未配置的错误码不应该重试")
+	require.Equal(t, int64(0), healthy.hits.Load())
+
+	pool = testPool(ctx, []*testNode{behind, healthy}, WithRetryPolicy(RetryPolicy{
+		MaxAttempts:    2,
+		RetryableCodes: []int{-32005},
+	}))
+	out, err := pool.GetBalance(ctx, solana.SystemProgramID, rpc.CommitmentProcessed)
+	require.NoError(t, err)
+	require.Equal(t, uint64(2), out.Value)
+}
+
+func Test_RetryPolicyBackoff(t *testing.T) {
+	p := RetryPolicy{BaseDelay: 100 * time.Millisecond, MaxDelay: time.Second}
+	for attempt := 1; attempt < 10; attempt++ {
+		d := p.backoff(attempt)
+		ceil := min(p.BaseDelay<<(attempt-1), p.MaxDelay)
+		require.GreaterOrEqual(t, d, ceil/2)
+		require.LessOrEqual(t, d, ceil)
+	}
+}
+
+func Test_ClientHealthCheck(t *testing.T) {
+	ctx := context.Background()
+	fresh, stale, down := newTestNode(t, 1), newTestNode(t, 2), newTestNode(t, 3)
+	fresh.slot.Store(1000)
+	stale.slot.Store(900)
+	var broken atomic.Bool
+	broken.Store(true)
+	down.handler = func(w http.ResponseWriter, req map[string]any) bool {
+		if broken.Load() {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return true
+		}
+		return false
+	}
+	down.slot.Store(1000)
+
+	pool := testPool(ctx, []*testNode{fresh, stale, down},
+		WithHealthCheck(HealthCheck{Interval: time.Hour, MaxSlotLag: 50}),
+		WithRetryPolicy(RetryPolicy{MaxAttempts: 1}),
+	)
+	defer pool.Close()
+	pool.CheckHealth(ctx)
+
+	require.True(t, pool.Endpoints()[0].Healthy())
+	require.False(t, pool.Endpoints()[1].Healthy(), "落后100个slot的节点应该被移出轮询")
+	require.False(t, pool.Endpoints()[2].Healthy(), "请求失败的节点应该被移出轮询")
+	for i := 0; i < 5; i++ {
+		out, err := pool.GetBalance(ctx, solana.SystemProgramID, rpc.CommitmentProcessed)
+		require.NoError(t, err)
+		require.Equal(t, uint64(1), out.Value)
+	}
+
+	stale.slot.Store(990)
+	broken.Store(false)
+	pool.CheckHealth(ctx)
+	for _, ep := range pool.Endpoints() {
+		require.True(t, ep.Healthy(), ep.Name)
+	}
+}
+
+func Test_ClientCircuitBreaker(t *testing.T) {
+	ctx := context.Background()
+	flaky, healthy := newTestNode(t, 1), newTestNode(t, 2)
+	var broken atomic.Bool
+	broken.Store(true)
+	flaky.handler = func(w http.ResponseWriter, req map[string]any) bool {
+		if broken.Load() {
+			w.WriteHeader(http.StatusInternalServerError)
+			return true
+		}
+		return false
+	}
+
+	var (
+		lock    sync.Mutex
+		changes []BreakerState
+	)
+	pool := testPool(ctx, []*testNode{flaky, healthy},
+		WithRetryPolicy(RetryPolicy{MaxAttempts: 2}),
+		WithCircuitBreaker(BreakerConfig{
+			ConsecutiveFailures: 2,
+			OpenTimeout:         50 * time.Millisecond,
+			OnStateChange: func(endpoint string, from, to BreakerState) {
+				lock.Lock()
+				defer lock.Unlock()
+				require.Equal(t, "1", endpoint)
+				changes = append(changes, to)
+			},
+		}),
+	)
+	defer pool.Close()
+
+	for i := 0; i < 10; i++ {
+		out, err := pool.GetBalance(ctx, solana.SystemProgramID, rpc.CommitmentProcessed)
+		require.NoError(t, err)
+		require.Equal(t, uint64(2), out.Value)
+	}
+	require.Equal(t, int64(2), flaky.hits.Load(), "熔断后不应该再请求该节点")
+	require.Equal(t, BreakerOpen, pool.Endpoints()[0].BreakerState())
+
+	broken.Store(false)
+	time.Sleep(60 * time.Millisecond)
+	for i := 0; i < 4; i++ {
+		_, err := pool.GetBalance(ctx, solana.SystemProgramID, rpc.CommitmentProcessed)
+		require.NoError(t, err)
+	}
+	require.Equal(t, BreakerClosed, pool.Endpoints()[0].BreakerState())
+
+	lock.Lock()
+	defer lock.Unlock()
+	require.Equal(t, []BreakerState{BreakerOpen, BreakerHalfOpen, BreakerClosed}, changes)
+}
+
+func Test_BreakerErrorRate(t *testing.T) {
+	b := newBreaker("test", BreakerConfig{
+		ConsecutiveFailures: 100,
+		ErrorRate:           0.5,
+		MinRequests:         10,
+		Window:              time.Minute,
+		OpenTimeout:         time.Minute,
+		HalfOpenRequests:    1,
+	})
+	for i := 0; i < 9; i++ {
+		b.record(i%2 == 0)
+	}
+	require.Equal(t, BreakerClosed, b.State(), "请求数不足时不计算错误率")
+	b.record(false)
+	require.Equal(t, BreakerOpen, b.State())
+	require.False(t, b.allow())
+}
+
+func Test_ClientHedging(t *testing.T) {
+	ctx := context.Background()
+	slow, fast := newTestNode(t, 1), newTestNode(t, 2)
+	release := make(chan struct{})
+	slow.handler = func(w http.ResponseWriter, req map[string]any) bool {
+		if req["method"] == "getBalance" {
+			select {
+			case <-release:
+			case <-time.After(2 * time.Second):
+			}
+		}
+		return false
+	}
+	defer close(release)
+
+	pool := testPool(ctx, []*testNode{slow, fast}, WithHedging(HedgePolicy{MaxDelay: 200 * time.Millisecond}))
+	defer pool.Close()
+
+	start := time.Now()
+	out, err := pool.GetBalance(ctx, solana.SystemProgramID, rpc.CommitmentProcessed)
+	require.NoError(t, err)
+	require.Equal(t, uint64(2), out.Value, "快节点的结果应该胜出")
+	require.Less(t, time.Since(start), time.Second)
+	require.Equal(t, int64(1), slow.hits.Load())
+	require.Equal(t, int64(1), fast.hits.Load())
+
+	// 只记录对冲请求自己的延迟，不包括等待对冲的时间
+	ring := pool.latency.samples["getBalance"]
+	require.Equal(t, 1, ring.count)
+	require.Less(t, ring.values[0], 200*time.Millisecond)
+
+	// sendTransaction 默认不进行对冲
+	var tx solana.Signature
+	for i := 0; i < 2; i++ {
+		pool.RPCCallForInto(ctx, &tx, "sendTransaction", []interface{}{"tx"})
+	}
+	require.Equal(t, int64(2), slow.hits.Load())
+	require.Equal(t, int64(2), fast.hits.Load())
+}
+
+func Test_HedgeApplies(t *testing.T) {
+	policy := &HedgePolicy{Methods: DefaultHedgeMethods}
+	require.True(t, policy.applies("getSlot"))
+	require.False(t, policy.applies("sendTransaction"))
+
+	// sendTransaction 需要同时在 Methods 中并且开启 SendTransaction
+	policy.SendTransaction = true
+	require.False(t, policy.applies("sendTransaction"))
+	policy.Methods = append(slices.Clone(DefaultHedgeMethods), "sendTransaction")
+	require.True(t, policy.applies("sendTransaction"))
+	policy.SendTransaction = false
+	require.False(t, policy.applies("sendTransaction"))
+}
+
+func Test_HedgeDelay(t *testing.T) {
+	policy := &HedgePolicy{Percentile: 0.9, MinDelay: time.Millisecond, MaxDelay: time.Second}
+	tracker := &latencyTracker{samples: map[string]*latencyRing{}}
+	require.Equal(t, time.Second, tracker.delay("getSlot", policy), "样本不足时使用 MaxDelay")
+
+	for i := 1; i <= 100; i++ {
+		tracker.observe("getSlot", time.Duration(i)*time.Millisecond)
+	}
+	require.Equal(t, 91*time.Millisecond, tracker.delay("getSlot", policy))
+}
+
+func Test_ClientRouting(t *testing.T) {
+	ctx := context.Background()
+	staked, archive, cheap1, cheap2 := newTestNode(t, 1), newTestNode(t, 2), newTestNode(t, 3), newTestNode(t, 4)
+	pool := NewRPCClient(ctx, rpc.LocalNet,
+		WithRPCClient(rpc.New(staked.URL), WithGroup("staked")),
+		WithRPCClient(rpc.New(archive.URL), WithGroup("archive")),
+		WithRPCClient(rpc.New(cheap1.URL), WithGroup("cheap")),
+		WithRPCClient(rpc.New(cheap2.URL), WithGroup("cheap")),
+		WithRoute("sendTransaction", "staked"),
+		WithRoute(MethodGroupHeavy, "archive"),
+		WithDefaultRoute("cheap"),
+	)
+	defer pool.Close()
+
+	var out json.RawMessage
+	require.NoError(t, pool.RPCCallForInto(ctx, &out, "sendTransaction", nil))
+	require.NoError(t, pool.RPCCallForInto(ctx, &out, "getProgramAccounts", nil))
+	require.NoError(t, pool.RPCCallForInto(ctx, &out, "getTransaction", nil))
+	for i := 0; i < 4; i++ {
+		require.NoError(t, pool.RPCCallForInto(ctx, &out, "getBalance", nil))
+	}
+	require.Equal(t, int64(1), staked.hits.Load())
+	require.Equal(t, int64(2), archive.hits.Load())
+	require.Equal(t, int64(2), cheap1.hits.Load())
+	require.Equal(t, int64(2), cheap2.hits.Load())
+
+	pool = NewRPCClient(ctx, rpc.LocalNet,
+		WithRPCClient(rpc.New(cheap1.URL)),
+		WithRoute(MethodGroupSend, "staked"),
+	)
+	err := pool.RPCCallForInto(ctx, &out, "sendTransaction", nil)
+	require.ErrorIs(t, err, ErrNoEndpoint)
+}
+
+func Test_ClientRoutingOverlap(t *testing.T) {
+	ctx := context.Background()
+	first, second := newTestNode(t, 1), newTestNode(t, 2)
+	pool := NewRPCClient(ctx, rpc.LocalNet,
+		WithRPCClient(rpc.New(first.URL), WithGroup("first")),
+		WithRPCClient(rpc.New(second.URL), WithGroup("second")),
+		WithMethodGroup("reads", "getBalance", "getSlot"),
+		WithMethodGroup("balances", "getBalance"),
+		WithRoute("reads", "first"),
+		WithRoute("balances", "second"),
+		WithRoute("reads", "first"), // 重复添加不改变优先级
+	)
+	defer pool.Close()
+
+	// 方法属于多个方法分组时总是使用最先添加的规则
+	var out json.RawMessage
+	for i := 0; i < 20; i++ {
+		require.NoError(t, pool.RPCCallForInto(ctx, &out, "getBalance", nil))
+	}
+	require.Equal(t, int64(20), first.hits.Load())
+	require.Zero(t, second.hits.Load())
+
+	proxied := NewRPCClient(ctx, rpc.LocalNet, WithRPCProxyOpts(first.URL, "http://127.0.0.1:1", 0, WithName("proxied"), WithGroup("staked")))
+	defer proxied.Close()
+	require.Equal(t, "proxied", proxied.Endpoints()[0].Name)
+	require.True(t, proxied.Endpoints()[0].InGroup("staked"))
+
+	// 兼容原来的调用方式
+	proxied = NewRPCClient(ctx, rpc.LocalNet, WithRPCProxy(first.URL, "http://127.0.0.1:1"), WithRPCProxy(second.URL, "http://127.0.0.1:1", 5))
+	defer proxied.Close()
+	require.Len(t, proxied.Endpoints(), 2)
+}
@@ -0,0 +1,132 @@
+package gosolana
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/gagliardetto/solana-go/rpc"
+)
+
+// ErrGenesisMismatch 节点的创世哈希与集群不一致，通常是把一个集群的节点地址配置到了另一个集群
+var ErrGenesisMismatch = errors.New("创世哈希不一致")
+
+// Cluster 集群配置
+type Cluster struct {
+	Name        string
+	RPC         string
+	WS          string
+	GenesisHash string // 集群的创世哈希，为空时无法校验节点
+}
+
+// 内置的集群
+var (
+	MainNet = Cluster{
+		Name:        "mainnet",
+		RPC:         rpc.MainNetBeta_RPC,
+		WS:          rpc.MainNetBeta_WS,
+		GenesisHash: "5eykt4UsFv8P8NJdTREpY1vzqKqZKvdpKuc147dw2N9d",
+	}
+	DevNet = Cluster{
+		Name:        "devnet",
+		RPC:         rpc.DevNet_RPC,
+		WS:          rpc.DevNet_WS,
+		GenesisHash: "EtWTRABZaYq6iMfeYKouRu166VU2xqa1wcaWoxPkrZBG",
+	}
+	TestNet = Cluster{
+		Name:        "testnet",
+		RPC:         rpc.TestNet_RPC,
+		WS:          rpc.TestNet_WS,
+		GenesisHash: "4uhcVJyU9pJkvQyS88uRDiswHXSCkY3zQawwpjk2NsNY",
+	}
+	// LocalNet solana-test-validator 每次重置都会生成新的创世哈希，因此没有预设
+	LocalNet = Cluster{
+		Name: "localnet",
+		RPC:  "http://127.0.0.1:8899",
+		WS:   "ws://127.0.0.1:8900",
+	}
+)
+
+var (
+	clusterLock sync.RWMutex
+	clusters    = map[string]Cluster{
+		MainNet.Name:  MainNet,
+		DevNet.Name:   DevNet,
+		TestNet.Name:  TestNet,
+		LocalNet.Name: LocalNet,
+	}
+	// clusterAliases 集群的其他常用名称
+	clusterAliases = map[string]string{
+		"mainnet-beta": MainNet.Name,
+		"localhost":    LocalNet.Name,
+	}
+)
+
+// RPCCluster 转换为 solana-go 的 rpc.Cluster
+func (c Cluster) RPCCluster() rpc.Cluster {
+	return rpc.Cluster{Name: c.Name, RPC: c.RPC, WS: c.WS}
+}
+
+// RegisterCluster 注册一个自定义集群，已经存在的同名集群会被覆盖
+func RegisterCluster(cluster Cluster) error {
+	cluster.Name = strings.ToLower(strings.TrimSpace(cluster.Name))
+	if cluster.Name == "" {
+		return errors.New("集群名称不能为空")
+	}
+	if err := validateURL(cluster.RPC, "http", "https"); err != nil {
+		return fmt.Errorf("集群 %s 的RPC地址无效: %w", cluster.Name, err)
+	}
+	if cluster.WS != "" {
+		if err := validateURL(cluster.WS, "ws", "wss"); err != nil {
+			return fmt.Errorf("集群 %s 的ws地址无效: %w", cluster.Name, err)
+		}
+	}
+
+	clusterLock.Lock()
+	defer clusterLock.Unlock()
+	clusters[cluster.Name] = cluster
+	return nil
+}
+
+// LookupCluster 按照名称查找集群，不区分大小写，支持 mainnet-beta 等别名
+func LookupCluster(name string) (Cluster, bool) {
+	name = strings.ToLower(strings.TrimSpace(name))
+
+	clusterLock.RLock()
+	defer clusterLock.RUnlock()
+	if cluster, ok := clusters[name]; ok {
+		return cluster, true
+	}
+	cluster, ok := clusters[clusterAliases[name]]
+	return cluster, ok
+}
+
+// Clusters 返回所有已注册的集群，按照名称排序
+func Clusters() []Cluster {
+	clusterLock.RLock()
+	defer clusterLock.RUnlock()
+
+	result := make([]Cluster, 0, len(clusters))
+	for _, cluster := range clusters {
+		result = append(result, cluster)
+	}
+	slices.SortFunc(result, func(a, b Cluster) int {
+		return strings.Compare(a.Name, b.Name)
+	})
+	return result
+}
+
+// VerifyGenesisHash 调用 getGenesisHash 校验节点属于期望的集群，不一致时返回的错误包含 ErrGenesisMismatch
+func VerifyGenesisHash(ctx context.Context, client *rpc.Client, expected string) error {
+	actual, err := client.GetGenesisHash(ctx)
+	if err != nil {
+		return fmt.Errorf("获取创世哈希失败: %w", err)
+	}
+	if actual.String() != expected {
+		return fmt.Errorf("%w: 期望 %s，节点返回 %s", ErrGenesisMismatch, expected, actual)
+	}
+	return nil
+}
@@ -0,0 +1,65 @@
+package gosolana
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/gagliardetto/solana-go/rpc"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_LookupCluster(t *testing.T) {
+	cluster, ok := LookupCluster("Mainnet-Beta")
+	require.True(t, ok)
+	require.Equal(t, MainNet, cluster)
+
+	cluster, ok = LookupCluster("localnet")
+	require.True(t, ok)
+	require.Equal(t, "http://127.0.0.1:8899", cluster.RPC)
+	require.Equal(t, "ws://127.0.0.1:8900", cluster.WS)
+
+	_, ok = LookupCluster("unknown")
+	require.False(t, ok)
+	require.Error(t, RegisterCluster(Cluster{Name: "bad", RPC: "127.0.0.1"}))
+}
+
+func Test_VerifyGenesis(t *testing.T) {
+	ctx := context.Background()
+	node := newTestNode(t, 1)
+	node.handler = func(w http.ResponseWriter, req map[string]any) bool {
+		if req["method"] != "getGenesisHash" {
+			return false
+		}
+		writeResult(w, req["id"], DevNet.GenesisHash)
+		return true
+	}
+
+	require.NoError(t, RegisterCluster(Cluster{Name: "test-devnet", RPC: node.URL, GenesisHash: DevNet.GenesisHash}))
+	_, err := NewWallet(ctx, Option{Cluster: "test-devnet", WsMode: WsDisabled, VerifyGenesis: true})
+	require.NoError(t, err)
+
+	// 主网私钥不应该在其他集群的节点上使用
+	_, err = NewWallet(ctx, Option{Cluster: "mainnet", RpcUrl: node.URL, WsMode: WsDisabled, VerifyGenesis: true})
+	require.ErrorIs(t, err, ErrGenesisMismatch)
+
+	// 节点池中任何一个节点在其他集群上都应该失败
+	other := newTestNode(t, 2)
+	other.handler = func(w http.ResponseWriter, req map[string]any) bool {
+		if req["method"] != "getGenesisHash" {
+			return false
+		}
+		writeResult(w, req["id"], MainNet.GenesisHash)
+		return true
+	}
+	pool := []ClientOption{WithRPCClient(rpc.New(other.URL), WithName("other"))}
+	_, err = NewWallet(ctx, Option{Cluster: "test-devnet", WsMode: WsDisabled, VerifyGenesis: true, RpcOptions: pool})
+	require.ErrorIs(t, err, ErrGenesisMismatch)
+	require.Contains(t, err.Error(), "other")
+
+	// 没有期望的创世哈希时无法校验
+	_, err = NewWallet(ctx, Option{Cluster: "localnet", RpcUrl: node.URL, WsMode: WsDisabled, VerifyGenesis: true})
+	var optErr *OptionError
+	require.ErrorAs(t, err, &optErr)
+	require.Equal(t, "GenesisHash", optErr.Field)
+}
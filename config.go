@@ -0,0 +1,360 @@
+package gosolana
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gagliardetto/solana-go/rpc"
+	"github.com/gagliardetto/solana-go/rpc/jsonrpc"
+	"gopkg.in/yaml.v3"
+)
+
+// EnvPrefix 配置项对应的环境变量前缀
+const EnvPrefix = "GOSOLANA_"
+
+// 支持的环境变量
+const (
+	EnvConfig             = EnvPrefix + "CONFIG"              // 配置文件路径，LoadConfig 的 path 为空时使用
+	EnvRpcUrl             = EnvPrefix + "RPC_URL"             // 主节点地址
+	EnvWsUrl              = EnvPrefix + "WS_URL"              // ws地址
+	EnvWsMode             = EnvPrefix + "WS_MODE"             // eager、lazy 或者 disabled
+	EnvHeaders            = EnvPrefix + "HEADERS"             // 请求头，格式为 key=value,key2=value2
+	EnvProxy              = EnvPrefix + "PROXY"               // HTTP代理
+	EnvWsProxy            = EnvPrefix + "WS_PROXY"            // ws代理
+	EnvTimeout            = EnvPrefix + "TIMEOUT"             // 请求超时，例如 5s
+	EnvRateLimit          = EnvPrefix + "RATE_LIMIT"          // 主节点每秒请求数
+	EnvEndpoints          = EnvPrefix + "ENDPOINTS"           // 额外节点地址，逗号分隔，会替换配置文件中的 endpoints
+	EnvPkey               = EnvPrefix + "PKEY"                // base58私钥
+	EnvCluster            = EnvPrefix + "CLUSTER"             // 集群名称，见 LookupCluster
+	EnvVerify             = EnvPrefix + "VERIFY_GENESIS"      // 是否校验创世哈希，true 或者 false
+	EnvKeystore           = EnvPrefix + "KEYSTORE"            // 私钥文件路径，见 Option.Keystore
+	EnvKeystorePassphrase = EnvPrefix + "KEYSTORE_PASSPHRASE" // 私钥文件的口令
+	EnvMnemonic           = EnvPrefix + "MNEMONIC"            // BIP39 助记词
+	EnvMnemonicPassphrase = EnvPrefix + "MNEMONIC_PASSPHRASE" // 助记词的口令
+	EnvAccount            = EnvPrefix + "ACCOUNT"             // 从助记词派生第几个账户
+)
+
+// Config 可以从配置文件和环境变量加载的配置，通过 Config.Option 转换为 Option
+//
+// 配置的优先级从低到高为：配置文件、GOSOLANA_* 环境变量、加载之后在代码中修改的字段。
+// 没有默认节点，必须配置 cluster 或者 rpc_url，ws_mode 不是 disabled 时必须配置 cluster 或者 ws_url
+type Config struct {
+	Cluster       string `json:"cluster" yaml:"cluster"` // rpc_url 和 ws_url 会覆盖集群的地址
+	VerifyGenesis bool   `json:"verify_genesis" yaml:"verify_genesis"`
+	GenesisHash   string `json:"genesis_hash" yaml:"genesis_hash"`
+
+	RpcUrl    string            `json:"rpc_url" yaml:"rpc_url"`
+	WsUrl     string            `json:"ws_url" yaml:"ws_url"`
+	WsMode    string            `json:"ws_mode" yaml:"ws_mode"` // eager（默认）、lazy 或者 disabled
+	Headers   map[string]string `json:"headers" yaml:"headers"`
+	Proxy     string            `json:"proxy" yaml:"proxy"`
+	WsProxy   string            `json:"ws_proxy" yaml:"ws_proxy"`
+	Timeout   string            `json:"timeout" yaml:"timeout"` // time.ParseDuration 格式，例如 5s
+	RateLimit int               `json:"rate_limit" yaml:"rate_limit"`
+	Endpoints []EndpointConfig  `json:"endpoints" yaml:"endpoints"`
+	// Routes 将方法或者方法分组路由到 endpoints 的 groups，按照顺序匹配，见 WithRoute
+	Routes []RouteConfig `json:"routes" yaml:"routes"`
+	// DefaultRoute 没有匹配 routes 的请求发送到的节点分组，为空时使用所有节点，见 WithDefaultRoute
+	DefaultRoute string `json:"default_route" yaml:"default_route"`
+
+	// Pkey base58私钥，建议使用 Keystore 或者通过 GOSOLANA_PKEY 设置，而不是写在配置文件中
+	Pkey string `json:"pkey" yaml:"pkey"`
+	// Keystore 私钥文件路径，口令建议通过 GOSOLANA_KEYSTORE_PASSPHRASE 设置
+	Keystore           string `json:"keystore" yaml:"keystore"`
+	KeystorePassphrase string `json:"keystore_passphrase" yaml:"keystore_passphrase"`
+	// Mnemonic BIP39 助记词，建议通过 GOSOLANA_MNEMONIC 设置
+	Mnemonic           string `json:"mnemonic" yaml:"mnemonic"`
+	MnemonicPassphrase string `json:"mnemonic_passphrase" yaml:"mnemonic_passphrase"`
+	Account            uint32 `json:"account" yaml:"account"`
+	DerivationPath     string `json:"derivation_path" yaml:"derivation_path"`
+}
+
+// EndpointConfig 节点池中额外节点的配置，未设置的代理、请求头和超时继承 Config 中的配置
+type EndpointConfig struct {
+	Url       string            `json:"url" yaml:"url"`
+	Name      string            `json:"name" yaml:"name"`
+	Weight    int               `json:"weight" yaml:"weight"`
+	Groups    []string          `json:"groups" yaml:"groups"`
+	Headers   map[string]string `json:"headers" yaml:"headers"`
+	Proxy     string            `json:"proxy" yaml:"proxy"`
+	RateLimit int               `json:"rate_limit" yaml:"rate_limit"`
+}
+
+// RouteConfig 路由规则
+type RouteConfig struct {
+	Match string `json:"match" yaml:"match"` // JSON-RPC 方法名或者方法分组，例如 sendTransaction、send、heavy
+	Group string `json:"group" yaml:"group"` // 节点分组，即 endpoints 中的 groups
+}
+
+// String 打印配置时隐藏私钥
+func (c Config) String() string {
+	return fmt.Sprintf("Config{Cluster: %q, RpcUrl: %q, WsUrl: %q, WsMode: %q, Endpoints: %d, Pkey: %s, Mnemonic: %s}",
+		c.Cluster, c.RpcUrl, c.WsUrl, c.WsMode, len(c.Endpoints), redactSecret(c.Pkey), redactSecret(c.Mnemonic))
+}
+
+// GoString 防止 %#v 打印出私钥
+func (c Config) GoString() string {
+	return c.String()
+}
+
+// LoadConfig 从配置文件和环境变量加载配置
+//
+// path 为空时使用 GOSOLANA_CONFIG 指定的文件，都为空时只从环境变量加载。
+// .json 文件按照JSON解析，其他文件按照YAML解析
+func LoadConfig(path string) (Config, error) {
+	var config Config
+	if path == "" {
+		path = os.Getenv(EnvConfig)
+	}
+	if path != "" {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return config, fmt.Errorf("读取配置文件失败: %w", err)
+		}
+		if strings.EqualFold(filepath.Ext(path), ".json") {
+			err = json.Unmarshal(data, &config)
+		} else {
+			err = yaml.Unmarshal(data, &config)
+		}
+		if err != nil {
+			return config, fmt.Errorf("解析配置文件 %s 失败: %w", path, err)
+		}
+	}
+	if err := config.applyEnv(os.LookupEnv); err != nil {
+		return config, err
+	}
+	return config, nil
+}
+
+// applyEnv 使用环境变量覆盖配置，设置为空字符串的环境变量会清空对应的配置
+func (c *Config) applyEnv(lookup func(string) (string, bool)) error {
+	strs := map[string]*string{
+		EnvRpcUrl:   &c.RpcUrl,
+		EnvWsUrl:    &c.WsUrl,
+		EnvWsMode:   &c.WsMode,
+		EnvProxy:    &c.Proxy,
+		EnvWsProxy:  &c.WsProxy,
+		EnvTimeout:  &c.Timeout,
+		EnvPkey:     &c.Pkey,
+		EnvCluster:  &c.Cluster,
+		EnvKeystore: &c.Keystore,
+	}
+	for key, field := range strs {
+		if value, ok := lookup(key); ok {
+			*field = strings.TrimSpace(value)
+		}
+	}
+
+	// 口令可能包含首尾空格，不做处理
+	if value, ok := lookup(EnvKeystorePassphrase); ok {
+		c.KeystorePassphrase = value
+	}
+	if value, ok := lookup(EnvMnemonicPassphrase); ok {
+		c.MnemonicPassphrase = value
+	}
+	if value, ok := lookup(EnvMnemonic); ok {
+		c.Mnemonic = value
+	}
+	if value, ok := lookup(EnvAccount); ok {
+		account, err := strconv.ParseUint(strings.TrimSpace(value), 10, 32)
+		if err != nil {
+			return &OptionError{Field: EnvAccount, Err: err}
+		}
+		c.Account = uint32(account)
+	}
+	if value, ok := lookup(EnvRateLimit); ok {
+		n, err := strconv.Atoi(strings.TrimSpace(value))
+		if err != nil {
+			return &OptionError{Field: EnvRateLimit, Err: err}
+		}
+		c.RateLimit = n
+	}
+	if value, ok := lookup(EnvVerify); ok {
+		verify, err := strconv.ParseBool(strings.TrimSpace(value))
+		if err != nil {
+			return &OptionError{Field: EnvVerify, Err: err}
+		}
+		c.VerifyGenesis = verify
+	}
+	if value, ok := lookup(EnvHeaders); ok {
+		headers := map[string]string{}
+		for _, pair := range splitList(value) {
+			k, v, found := strings.Cut(pair, "=")
+			if !found || strings.TrimSpace(k) == "" {
+				return &OptionError{Field: EnvHeaders, Err: fmt.Errorf("请求头 %q 的格式应该为 key=value", pair)}
+			}
+			headers[strings.TrimSpace(k)] = strings.TrimSpace(v)
+		}
+		c.Headers = headers
+	}
+	if value, ok := lookup(EnvEndpoints); ok {
+		c.Endpoints = nil
+		for _, u := range splitList(value) {
+			c.Endpoints = append(c.Endpoints, EndpointConfig{Url: u})
+		}
+	}
+	return nil
+}
+
+// splitList 按逗号分割并去掉空白项
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// Option 将配置转换为 Option 并校验，不会发起任何网络请求
+//
+// 配置了 endpoints 时 rpc_url 和所有额外节点组成 Client 节点池
+func (c Config) Option() (Option, error) {
+	option := Option{
+		RpcUrl:    c.RpcUrl,
+		WsUrl:     c.WsUrl,
+		Headers:   c.Headers,
+		Proxy:     c.Proxy,
+		WsProxy:   c.WsProxy,
+		RateLimit: c.RateLimit,
+		Pkey:      c.Pkey,
+
+		Cluster:       c.Cluster,
+		VerifyGenesis: c.VerifyGenesis,
+		GenesisHash:   c.GenesisHash,
+
+		Keystore:           c.Keystore,
+		KeystorePassphrase: c.KeystorePassphrase,
+
+		Mnemonic:           c.Mnemonic,
+		MnemonicPassphrase: c.MnemonicPassphrase,
+		Account:            c.Account,
+		DerivationPath:     c.DerivationPath,
+	}
+	if c.RpcUrl == "" && c.Cluster == "" {
+		return option, &OptionError{Field: "RpcUrl", Err: fmt.Errorf("没有配置节点地址，请在配置文件中设置 cluster 或 rpc_url，或者设置环境变量 %s 或 %s", EnvCluster, EnvRpcUrl)}
+	}
+
+	switch strings.ToLower(c.WsMode) {
+	case "", "eager":
+		option.WsMode = WsEager
+	case "lazy":
+		option.WsMode = WsLazy
+	case "disabled":
+		option.WsMode = WsDisabled
+	default:
+		return option, &OptionError{Field: "WsMode", Err: fmt.Errorf("未知的ws连接方式 %q，需要 eager/lazy/disabled", c.WsMode)}
+	}
+	if c.WsUrl == "" && c.Cluster == "" && option.WsMode != WsDisabled {
+		return option, &OptionError{Field: "WsUrl", Err: fmt.Errorf("没有配置ws地址，请设置 ws_url 或者环境变量 %s，不需要订阅时可以将 ws_mode 设置为 disabled", EnvWsUrl)}
+	}
+
+	if c.Timeout != "" {
+		timeout, err := time.ParseDuration(c.Timeout)
+		if err != nil {
+			return option, &OptionError{Field: "TimeOut", Err: err}
+		}
+		option.TimeOut = timeout
+	}
+	if err := option.Validate(); err != nil {
+		return option, err
+	}
+
+	for i, endpoint := range c.Endpoints {
+		clientOption, err := c.endpointOption(endpoint, option.TimeOut)
+		if err != nil {
+			return option, &OptionError{Field: fmt.Sprintf("Endpoints[%d]", i), Err: err}
+		}
+		option.RpcOptions = append(option.RpcOptions, clientOption)
+	}
+
+	groups := map[string]bool{}
+	for _, endpoint := range c.Endpoints {
+		for _, group := range endpoint.Groups {
+			groups[group] = true
+		}
+	}
+	for i, route := range c.Routes {
+		if route.Match == "" || !groups[route.Group] {
+			return option, &OptionError{Field: fmt.Sprintf("Routes[%d]", i), Err: fmt.Errorf("路由 %q 的节点分组 %q 不存在于任何节点的 groups 中", route.Match, route.Group)}
+		}
+		option.RpcOptions = append(option.RpcOptions, WithRoute(route.Match, route.Group))
+	}
+	if c.DefaultRoute != "" {
+		if !groups[c.DefaultRoute] {
+			return option, &OptionError{Field: "DefaultRoute", Err: fmt.Errorf("节点分组 %q 不存在于任何节点的 groups 中", c.DefaultRoute)}
+		}
+		option.RpcOptions = append(option.RpcOptions, WithDefaultRoute(c.DefaultRoute))
+	}
+	return option, nil
+}
+
+// endpointOption 创建额外节点的客户端
+func (c Config) endpointOption(endpoint EndpointConfig, timeout time.Duration) (ClientOption, error) {
+	if err := validateURL(endpoint.Url, "http", "https"); err != nil {
+		return nil, err
+	}
+	if endpoint.RateLimit < 0 {
+		return nil, fmt.Errorf("速率限制不能为负数: %d", endpoint.RateLimit)
+	}
+
+	httpClient := &http.Client{}
+	if proxy := cmp.Or(endpoint.Proxy, c.Proxy); proxy != "" {
+		if err := validateURL(proxy, "http", "https", "socks5"); err != nil {
+			return nil, err
+		}
+		client, err := NewProxyHttpClient(proxy)
+		if err != nil {
+			return nil, err
+		}
+		httpClient = client
+	}
+	httpClient.Timeout = cmp.Or(timeout, 5*time.Second)
+
+	headers := make(map[string]string, len(c.Headers)+len(endpoint.Headers))
+	for k, v := range c.Headers {
+		headers[k] = v
+	}
+	for k, v := range endpoint.Headers {
+		headers[k] = v
+	}
+	opts := &jsonrpc.RPCClientOpts{HTTPClient: httpClient, CustomHeaders: headers}
+
+	var client rpc.JSONRPCClient
+	if endpoint.RateLimit > 0 {
+		client = NewWithRateLimit(endpoint.Url, endpoint.RateLimit, opts)
+	} else {
+		client = jsonrpc.NewClientWithOpts(endpoint.Url, opts)
+	}
+
+	endpointOpts := []EndpointOption{WithName(cmp.Or(endpoint.Name, endpointHost(endpoint.Url)))}
+	if endpoint.Weight > 0 {
+		endpointOpts = append(endpointOpts, WithWeight(endpoint.Weight))
+	}
+	if len(endpoint.Groups) > 0 {
+		endpointOpts = append(endpointOpts, WithGroup(endpoint.Groups...))
+	}
+	return WithRPCClient(rpc.NewWithCustomRPCClient(client), endpointOpts...), nil
+}
+
+// LoadOption 从配置文件和环境变量加载配置并创建 Option，见 LoadConfig 和 NewOption
+func LoadOption(ctx context.Context, path string) (Option, error) {
+	config, err := LoadConfig(path)
+	if err != nil {
+		return Option{}, err
+	}
+	option, err := config.Option()
+	if err != nil {
+		return option, err
+	}
+	return NewOption(ctx, option)
+}
@@ -0,0 +1,100 @@
+package gosolana
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_LoadConfig(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "gosolana.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(`
+rpc_url: https://api.testnet.solana.com
+ws_url: wss://api.testnet.solana.com
+timeout: 3s
+headers:
+  x-api-key: file
+endpoints:
+  - url: https://backup.example.com
+    weight: 2
+    groups: [archive]
+routes:
+  - match: heavy
+    group: archive
+default_route: archive
+`), 0o600))
+
+	// 环境变量覆盖配置文件
+	t.Setenv(EnvWsMode, "disabled")
+	t.Setenv(EnvHeaders, "x-api-key=env")
+	config, err := LoadConfig(path)
+	require.NoError(t, err)
+	require.Equal(t, "https://api.testnet.solana.com", config.RpcUrl)
+	require.Equal(t, "env", config.Headers["x-api-key"])
+	require.Len(t, config.Endpoints, 1)
+
+	option, err := config.Option()
+	require.NoError(t, err)
+	require.Equal(t, WsDisabled, option.WsMode)
+	require.Equal(t, 3*time.Second, option.TimeOut)
+	require.Len(t, option.RpcOptions, 3)
+
+	option, err = LoadOption(context.Background(), path)
+	require.NoError(t, err)
+	require.Nil(t, option.WsClient)
+	require.NotNil(t, option.RpcClient)
+	require.Empty(t, option.RpcOptions, "节点池已经创建，返回的配置可以再次使用")
+	_, err = NewOption(context.Background(), option)
+	require.NoError(t, err)
+
+	// 路由到不存在的节点分组
+	config.Routes = append(config.Routes, RouteConfig{Match: "send", Group: "staked"})
+	_, err = config.Option()
+	var optErr *OptionError
+	require.ErrorAs(t, err, &optErr)
+	require.Equal(t, "Routes[1]", optErr.Field)
+}
+
+func Test_LoadConfigJSON(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "gosolana.json")
+	require.NoError(t, os.WriteFile(path, []byte(`{"rpc_url": "http://127.0.0.1:8899", "ws_mode": "lazy", "rate_limit": 10}`), 0o600))
+
+	config, err := LoadConfig(path)
+	require.NoError(t, err)
+	require.Equal(t, 10, config.RateLimit)
+
+	// lazy 模式也需要ws地址
+	_, err = config.Option()
+	var optErr *OptionError
+	require.True(t, errors.As(err, &optErr))
+	require.Equal(t, "WsUrl", optErr.Field)
+
+	t.Setenv(EnvWsUrl, "ws://127.0.0.1:8900")
+	config, err = LoadConfig(path)
+	require.NoError(t, err)
+	option, err := config.Option()
+	require.NoError(t, err)
+	require.Equal(t, WsLazy, option.WsMode)
+}
+
+func Test_LoadConfigRequiresRpcUrl(t *testing.T) {
+	t.Setenv(EnvConfig, "")
+	t.Setenv(EnvRpcUrl, "")
+	config, err := LoadConfig("")
+	require.NoError(t, err)
+
+	_, err = config.Option()
+	var optErr *OptionError
+	require.True(t, errors.As(err, &optErr), "没有配置节点时不应该使用 DevNet")
+	require.Equal(t, "RpcUrl", optErr.Field)
+
+	t.Setenv(EnvRateLimit, "many")
+	_, err = LoadConfig("")
+	require.ErrorAs(t, err, &optErr)
+}
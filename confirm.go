@@ -0,0 +1,156 @@
+package gosolana
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/gagliardetto/solana-go"
+	"github.com/gagliardetto/solana-go/rpc"
+	"github.com/go-enols/gosolana/log"
+)
+
+const (
+	// MaxProcessingAge 区块哈希在多少个区块之后过期
+	MaxProcessingAge = 150
+	// DefaultRebroadcastInterval 等待确认时重新广播交易的默认间隔
+	DefaultRebroadcastInterval = 2 * time.Second
+)
+
+// ErrBlockhashExpired 区块高度已经超过了交易区块哈希的最后有效高度，交易不会再被打包，
+// 调用方可以使用新的区块哈希重新签名并发送，不会重复执行
+var ErrBlockhashExpired = errors.New("交易的区块哈希已过期")
+
+// SendSignedTransactionWithOpts 发送已经完成签名的交易并等待确认，见 SendOptions 中的确认配置
+//
+// 交易以 maxRetries=0 发送，由钱包每隔 RebroadcastInterval 重新广播，直到交易达到确认等级、执行失败、
+// 区块哈希过期或者 ctx 被取消。区块哈希过期时返回 ErrBlockhashExpired
+func (w *Wallet) SendSignedTransactionWithOpts(ctx context.Context, tx *solana.Transaction, opts SendOptions) (bool, error) {
+	if missing := MissingSigners(tx); len(missing) > 0 {
+		return false, &MissingSignersError{PublicKeys: missing}
+	}
+	commitment := cmp.Or(opts.Commitment, rpc.CommitmentProcessed)
+	lastValid := opts.LastValidBlockHeight
+	if lastValid == 0 {
+		// 不知道区块哈希的有效期时按照从现在开始计算，交易的区块哈希越旧，实际过期得越早
+		height, err := w.GetClient().GetBlockHeight(ctx, commitment)
+		if err != nil {
+			log.Error("获取区块高度失败", "error", err)
+			return false, err
+		}
+		lastValid = height + MaxProcessingAge
+	}
+	raw, err := tx.MarshalBinary()
+	if err != nil {
+		return false, fmt.Errorf("序列化交易失败: %w", err)
+	}
+
+	sig, err := w.broadcast(ctx, raw, opts.SkipPreflight)
+	if err != nil {
+		log.Error("发送交易失败", "error", err)
+		return false, err
+	}
+	log.Info("交易已发送", "signature", sig, "lastValidBlockHeight", lastValid)
+	log.Debug("交易详情", "transaction", tx)
+	return w.confirmTransaction(ctx, sig, raw, lastValid, commitment, cmp.Or(opts.RebroadcastInterval, DefaultRebroadcastInterval))
+}
+
+// broadcast 发送交易，maxRetries=0 让节点不再自己重试，由钱包负责重新广播
+func (w *Wallet) broadcast(ctx context.Context, raw []byte, skipPreflight bool) (solana.Signature, error) {
+	maxRetries := uint(0)
+	return w.GetClient().SendRawTransactionWithOpts(ctx, raw, rpc.TransactionOpts{
+		SkipPreflight:       skipPreflight,
+		PreflightCommitment: rpc.CommitmentProcessed,
+		MaxRetries:          &maxRetries,
+	})
+}
+
+// confirmTransaction 轮询交易状态并定期重新广播，直到交易确认、执行失败或者区块哈希过期
+func (w *Wallet) confirmTransaction(ctx context.Context, sign solana.Signature, raw []byte, lastValid uint64, commitment rpc.CommitmentType, interval time.Duration) (bool, error) {
+	ticker := time.NewTicker(min(pollInterval, interval))
+	defer ticker.Stop()
+	sentAt := time.Now()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return false, ctx.Err()
+		case <-ticker.C:
+		}
+
+		confirmed, err := w.signatureConfirmed(ctx, sign, commitment)
+		if confirmed || err != nil {
+			return confirmed, err
+		}
+
+		// processed 的区块可能在少数分叉上，过期只能按照 confirmed 的区块高度判断
+		height, err := w.GetClient().GetBlockHeight(ctx, rpc.CommitmentConfirmed)
+		if err != nil {
+			log.Warn("获取区块高度失败", "error", err)
+		} else if height > lastValid {
+			// 区块哈希过期之前交易可能刚好被打包，只有成功查询到交易没有上链时才认为已经过期，
+			// 否则调用方重新签名发送可能会重复执行
+			status, err := w.signatureStatus(ctx, sign)
+			if err != nil {
+				log.Error("查询交易状态失败", "signature", sign, "error", err)
+				return false, fmt.Errorf("无法确认交易 %s 是否已经上链: %w", sign, err)
+			}
+			if status != nil {
+				// 交易已经上链，只是还没有达到要求的确认等级
+				if confirmed, err := statusConfirmed(sign, status, commitment); confirmed || err != nil {
+					return confirmed, err
+				}
+				continue
+			}
+			log.Warn("交易的区块哈希已过期", "signature", sign, "blockHeight", height, "lastValidBlockHeight", lastValid)
+			return false, fmt.Errorf("%w: 交易 %s 在区块高度 %d 之前没有被确认", ErrBlockhashExpired, sign, lastValid)
+		}
+
+		if time.Since(sentAt) >= interval {
+			sentAt = time.Now()
+			if _, err := w.broadcast(ctx, raw, true); err != nil {
+				log.Debug("重新广播交易失败", "signature", sign, "error", err)
+			}
+		}
+	}
+}
+
+// signatureConfirmed 查询一次交易状态，查询失败时当作还没有确认，交易执行失败时返回错误
+func (w *Wallet) signatureConfirmed(ctx context.Context, sign solana.Signature, commitment rpc.CommitmentType) (bool, error) {
+	status, err := w.signatureStatus(ctx, sign)
+	if err != nil {
+		log.Warn("查询交易状态失败", "signature", sign, "error", err)
+		return false, nil
+	}
+	return statusConfirmed(sign, status, commitment)
+}
+
+// signatureStatus 查询一次交易状态，交易还没有上链时返回 nil
+func (w *Wallet) signatureStatus(ctx context.Context, sign solana.Signature) (*rpc.SignatureStatusesResult, error) {
+	out, err := w.GetClient().GetSignatureStatuses(ctx, false, sign)
+	if err != nil {
+		return nil, err
+	}
+	if len(out.Value) == 0 {
+		return nil, nil
+	}
+	return out.Value[0], nil
+}
+
+// statusConfirmed 交易状态是否已经达到确认等级，交易执行失败时返回错误
+func statusConfirmed(sign solana.Signature, status *rpc.SignatureStatusesResult, commitment rpc.CommitmentType) (bool, error) {
+	if status == nil {
+		return false, nil
+	}
+	if status.Err != nil {
+		log.Warn("交易执行失败", "signature", sign, "error", status.Err)
+		return false, fmt.Errorf("交易执行失败: %v", status.Err)
+	}
+	if confirmationReached(status.ConfirmationStatus, commitment) {
+		log.Info("交易已确认", "signature", sign)
+		return true, nil
+	}
+	return false, nil
+}
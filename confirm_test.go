@@ -0,0 +1,116 @@
+package gosolana
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gagliardetto/solana-go"
+	"github.com/gagliardetto/solana-go/programs/system"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newConfirmNode 交易在发送 landAfter 次之后才会被确认，区块高度为 height，landAfter 为0时交易永远不会被确认
+func newConfirmNode(t *testing.T, landAfter int64, height uint64) (*testNode, *atomic.Int64) {
+	node, _ := newTxNode(t)
+	sends := &atomic.Int64{}
+	var seen sync.Map
+	next := node.handler
+	node.handler = func(w http.ResponseWriter, req map[string]any) bool {
+		switch req["method"] {
+		case "sendTransaction":
+			params := req["params"].([]any)
+			config := params[1].(map[string]any)
+			_, resent := seen.LoadOrStore(params[0], true)
+			if !assert.Equal(t, float64(0), config["maxRetries"]) ||
+				resent && !assert.Equal(t, true, config["skipPreflight"], "重新广播时应该跳过预检") {
+				http.Error(w, "unexpected sendTransaction options", http.StatusBadRequest)
+				return true
+			}
+			sends.Add(1)
+			return next(w, req)
+		case "getBlockHeight":
+			// 过期时的区块高度需要按照 confirmed 查询，processed 的区块高度已经超过了有效期
+			current := height
+			if params, _ := req["params"].([]any); len(params) == 0 || params[0].(map[string]any)["commitment"] != "confirmed" {
+				current += MaxProcessingAge
+			}
+			writeResult(w, req["id"], current)
+		case "getSignatureStatuses":
+			if landAfter == 0 || sends.Load() < landAfter {
+				writeResult(w, req["id"], map[string]any{
+					"context": map[string]any{"slot": 1},
+					"value":   []any{nil},
+				})
+				return true
+			}
+			return next(w, req)
+		default:
+			return next(w, req)
+		}
+		return true
+	}
+	return node, sends
+}
+
+func Test_SendRebroadcast(t *testing.T) {
+	ctx := context.Background()
+	node, sends := newConfirmNode(t, 3, 50)
+	wallet, err := NewWallet(ctx, Option{RpcUrl: node.URL, WsMode: WsDisabled})
+	require.NoError(t, err)
+	transfer := []solana.Instruction{system.NewTransferInstruction(1, wallet.PublicKey(), solana.NewWallet().PublicKey()).Build()}
+
+	ok, err := wallet.SendTransactionWithOpts(ctx, transfer, SendOptions{RebroadcastInterval: 20 * time.Millisecond})
+	require.NoError(t, err)
+	require.True(t, ok)
+	require.Equal(t, int64(3), sends.Load())
+}
+
+func Test_SendBlockhashExpired(t *testing.T) {
+	ctx := context.Background()
+	// newTxNode 返回的 lastValidBlockHeight 为100
+	node, sends := newConfirmNode(t, 0, 101)
+	wallet, err := NewWallet(ctx, Option{RpcUrl: node.URL, WsMode: WsDisabled})
+	require.NoError(t, err)
+	transfer := []solana.Instruction{system.NewTransferInstruction(1, wallet.PublicKey(), solana.NewWallet().PublicKey()).Build()}
+
+	builder := wallet.NewTxBuilder().AddInstruction(transfer...)
+	_, err = builder.Send(ctx, SendOptions{RebroadcastInterval: 20 * time.Millisecond})
+	require.ErrorIs(t, err, ErrBlockhashExpired)
+	require.Equal(t, uint64(100), builder.LastValidBlockHeight())
+	require.Equal(t, int64(1), sends.Load())
+
+	// 不知道有效期的交易按照当前区块高度估算，不会立即过期
+	tx, err := builder.AddInstruction(system.NewTransferInstruction(2, wallet.PublicKey(), solana.NewWallet().PublicKey()).Build()).BuildAndSign(ctx)
+	require.NoError(t, err)
+	ctx, cancel := context.WithTimeout(ctx, 100*time.Millisecond)
+	defer cancel()
+	_, err = wallet.SendSignedTransactionWithOpts(ctx, tx, SendOptions{RebroadcastInterval: 20 * time.Millisecond})
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+	require.Greater(t, sends.Load(), int64(2))
+}
+
+func Test_SendExpiredStatusUnavailable(t *testing.T) {
+	ctx := context.Background()
+	node, _ := newConfirmNode(t, 0, 101)
+	next := node.handler
+	node.handler = func(w http.ResponseWriter, req map[string]any) bool {
+		if req["method"] == "getSignatureStatuses" {
+			http.Error(w, "unavailable", http.StatusServiceUnavailable)
+			return true
+		}
+		return next(w, req)
+	}
+	wallet, err := NewWallet(ctx, Option{RpcUrl: node.URL, WsMode: WsDisabled})
+	require.NoError(t, err)
+	transfer := []solana.Instruction{system.NewTransferInstruction(1, wallet.PublicKey(), solana.NewWallet().PublicKey()).Build()}
+
+	// 无法确认交易是否已经上链时不能返回 ErrBlockhashExpired，否则调用方重新签名可能会重复执行
+	_, err = wallet.NewTxBuilder().AddInstruction(transfer...).Send(ctx, SendOptions{RebroadcastInterval: 20 * time.Millisecond})
+	require.Error(t, err)
+	require.NotErrorIs(t, err, ErrBlockhashExpired)
+}
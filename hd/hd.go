@@ -0,0 +1,134 @@
+// Package hd 从 BIP39 助记词派生 Solana 钱包
+//
+// 使用 SLIP-0010 的 ed25519 派生方式和 Phantom、Solflare 相同的路径 m/44'/501'/{account}'/0'，
+// 同一个助记词和口令派生出的地址与这些钱包一致
+package hd
+
+import (
+	"crypto/ed25519"
+	"crypto/hmac"
+	"crypto/sha512"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/gagliardetto/solana-go"
+	"github.com/tyler-smith/go-bip39"
+)
+
+// HardenedOffset 强化派生的索引偏移，ed25519 只支持强化派生
+const HardenedOffset uint32 = 0x80000000
+
+// ErrInvalidMnemonic 助记词无效，单词不在词表中或者校验和错误
+var ErrInvalidMnemonic = errors.New("无效的助记词")
+
+// Path 返回 Phantom、Solflare 等钱包使用的第 account 个账户的派生路径
+func Path(account uint32) string {
+	return fmt.Sprintf("m/44'/501'/%d'/0'", account)
+}
+
+// NewMnemonic 生成新的助记词
+//
+//	bits 熵的位数，128 对应12个单词，256 对应24个单词
+func NewMnemonic(bits int) (string, error) {
+	entropy, err := bip39.NewEntropy(bits)
+	if err != nil {
+		return "", err
+	}
+	return bip39.NewMnemonic(entropy)
+}
+
+// ValidateMnemonic 检查助记词的单词和校验和
+func ValidateMnemonic(mnemonic string) bool {
+	return bip39.IsMnemonicValid(normalize(mnemonic))
+}
+
+// Seed 使用助记词和可选的口令（BIP39 的第25个单词）生成种子
+func Seed(mnemonic, passphrase string) ([]byte, error) {
+	seed, err := bip39.NewSeedWithErrorChecking(normalize(mnemonic), passphrase)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidMnemonic, err)
+	}
+	return seed, nil
+}
+
+// FromMnemonic 从助记词派生第 account 个账户的私钥
+func FromMnemonic(mnemonic, passphrase string, account uint32) (solana.PrivateKey, error) {
+	keys, err := DeriveAccounts(mnemonic, passphrase, account, 1)
+	if err != nil {
+		return nil, err
+	}
+	return keys[0], nil
+}
+
+// DeriveAccounts 从助记词派生第 start 到 start+count-1 个账户的私钥，种子只计算一次
+func DeriveAccounts(mnemonic, passphrase string, start, count uint32) ([]solana.PrivateKey, error) {
+	seed, err := Seed(mnemonic, passphrase)
+	if err != nil {
+		return nil, err
+	}
+	defer clear(seed)
+
+	keys := make([]solana.PrivateKey, 0, count)
+	for i := uint32(0); i < count; i++ {
+		key, err := DeriveKey(seed, Path(start+i))
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, key)
+	}
+	return keys, nil
+}
+
+// DeriveKey 使用 SLIP-0010 从种子派生指定路径的私钥，路径的每一级都必须是强化派生，例如 m/44'/501'/0'/0'
+func DeriveKey(seed []byte, path string) (solana.PrivateKey, error) {
+	indexes, err := ParsePath(path)
+	if err != nil {
+		return nil, err
+	}
+
+	key, chain := hmacSHA512([]byte("ed25519 seed"), seed)
+	data := make([]byte, 1+32+4)
+	for _, index := range indexes {
+		copy(data[1:33], key)
+		binary.BigEndian.PutUint32(data[33:], index)
+		key, chain = hmacSHA512(chain, data)
+	}
+	clear(data)
+	return solana.PrivateKey(ed25519.NewKeyFromSeed(key)), nil
+}
+
+// ParsePath 解析派生路径，返回每一级加上 HardenedOffset 之后的索引
+func ParsePath(path string) ([]uint32, error) {
+	parts := strings.Split(strings.TrimSpace(path), "/")
+	if len(parts) == 0 || parts[0] != "m" {
+		return nil, fmt.Errorf("派生路径 %q 必须以 m 开头", path)
+	}
+	indexes := make([]uint32, 0, len(parts)-1)
+	for _, part := range parts[1:] {
+		trimmed := strings.TrimRight(part, "'h")
+		if trimmed == part {
+			return nil, fmt.Errorf("派生路径 %q 中的 %q 不是强化派生，ed25519 只支持强化派生", path, part)
+		}
+		index, err := strconv.ParseUint(trimmed, 10, 32)
+		if err != nil || uint32(index) >= HardenedOffset {
+			return nil, fmt.Errorf("派生路径 %q 中的 %q 无效", path, part)
+		}
+		indexes = append(indexes, uint32(index)+HardenedOffset)
+	}
+	return indexes, nil
+}
+
+func hmacSHA512(key, data []byte) ([]byte, []byte) {
+	mac := hmac.New(sha512.New, key)
+	mac.Write(data)
+	sum := mac.Sum(nil)
+	return sum[:32], sum[32:]
+}
+
+// normalize 去掉多余的空白，助记词经常从不同的地方复制
+func normalize(mnemonic string) string {
+	return strings.Join(strings.Fields(mnemonic), " ")
+}
@@ -0,0 +1,57 @@
+package hd
+
+import (
+	"encoding/hex"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// SLIP-0010 ed25519 的测试向量1
+func Test_DeriveKeySLIP10(t *testing.T) {
+	seed, _ := hex.DecodeString("000102030405060708090a0b0c0d0e0f")
+	cases := map[string]string{
+		"m":          "2b4be7f19ee27bbf30c667b642d5f4aa69fd169872f8fc3059c08ebae2eb19e7",
+		"m/0'":       "68e0fe46dfb67e368c75379acec591dad19df3cde26e63b93a8e704f1dade7a3",
+		"m/0'/1'":    "b1d0bad404bf35da785a64ca1ac54b2617211d2777696fbffaf208f746ae84f2",
+		"m/0h/1h/2h": "92a5b23c0b8a99e37d07df3fb9966917f5d06e02ddbd909c7e184371463e9fc9",
+	}
+	for path, expected := range cases {
+		key, err := DeriveKey(seed, path)
+		require.NoError(t, err, path)
+		require.Equal(t, expected, hex.EncodeToString(key[:32]), path)
+	}
+
+	_, err := DeriveKey(seed, "m/44'/501'/0")
+	require.Error(t, err, "ed25519 只支持强化派生")
+}
+
+func Test_FromMnemonic(t *testing.T) {
+	mnemonic := "abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon about"
+	key, err := FromMnemonic(mnemonic, "", 0)
+	require.NoError(t, err)
+	// Phantom 导入同一个助记词得到的第一个地址
+	require.Equal(t, "HAgk14JpMQLgt6rVgv7cBQFJWFto5Dqxi472uT3DKpqk", key.PublicKey().String())
+
+	keys, err := DeriveAccounts("  "+strings.ReplaceAll(mnemonic, " ", "\n")+" ", "", 0, 3)
+	require.NoError(t, err)
+	require.Len(t, keys, 3)
+	require.Equal(t, key, keys[0])
+	require.NotEqual(t, keys[1], keys[2])
+
+	withPassphrase, err := FromMnemonic(mnemonic, "TREZOR", 0)
+	require.NoError(t, err)
+	require.NotEqual(t, key, withPassphrase)
+
+	_, err = FromMnemonic("abandon abandon abandon", "", 0)
+	require.ErrorIs(t, err, ErrInvalidMnemonic)
+}
+
+func Test_NewMnemonic(t *testing.T) {
+	mnemonic, err := NewMnemonic(256)
+	require.NoError(t, err)
+	require.Len(t, strings.Fields(mnemonic), 24)
+	require.True(t, ValidateMnemonic(mnemonic))
+	require.Equal(t, "m/44'/501'/7'/0'", Path(7))
+}
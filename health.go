@@ -0,0 +1,111 @@
+package gosolana
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/gagliardetto/solana-go/rpc"
+	"github.com/go-enols/gosolana/log"
+)
+
+// HealthCheck 节点健康检查配置
+//
+// 检查时会对每个节点调用 getHealth 和 getSlot，请求失败或者 slot 落后于所有节点中最高的 slot 超过 MaxSlotLag 的节点会被移出轮询，
+// 之后的检查通过时自动恢复
+type HealthCheck struct {
+	Interval   time.Duration      // 检查间隔，默认10秒
+	Timeout    time.Duration      // 单个节点的检查超时，默认5秒
+	MaxSlotLag uint64             // 允许落后的最大slot数量，默认50，大约20秒
+	Commitment rpc.CommitmentType // getSlot 使用的确认等级，默认 processed
+}
+
+// WithHealthCheck 开启后台节点健康检查
+func WithHealthCheck(check HealthCheck) ClientOption {
+	return func(ctx context.Context, client *Client) {
+		if check.Interval <= 0 {
+			check.Interval = 10 * time.Second
+		}
+		if check.Timeout <= 0 {
+			check.Timeout = 5 * time.Second
+		}
+		if check.MaxSlotLag == 0 {
+			check.MaxSlotLag = 50
+		}
+		if check.Commitment == "" {
+			check.Commitment = rpc.CommitmentProcessed
+		}
+		client.health = &check
+	}
+}
+
+// CheckHealth 立即对所有节点执行一次健康检查
+func (c *Client) CheckHealth(ctx context.Context) {
+	if c.health == nil {
+		return
+	}
+
+	type status struct {
+		slot uint64
+		err  error
+	}
+	results := make([]status, len(c.endpoints))
+
+	var wg sync.WaitGroup
+	for i, ep := range c.endpoints {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			ctx, cancel := context.WithTimeout(ctx, c.health.Timeout)
+			defer cancel()
+
+			if _, err := ep.rpc.GetHealth(ctx); err != nil {
+				results[i].err = err
+				return
+			}
+			results[i].slot, results[i].err = ep.rpc.GetSlot(ctx, c.health.Commitment)
+		}()
+	}
+	wg.Wait()
+
+	var best uint64
+	for _, r := range results {
+		if r.err == nil {
+			best = max(best, r.slot)
+		}
+	}
+
+	for i, ep := range c.endpoints {
+		r := results[i]
+		healthy := r.err == nil && r.slot+c.health.MaxSlotLag >= best
+		if r.err == nil {
+			ep.slot.Store(r.slot)
+		}
+		if ep.healthy.Swap(healthy) == healthy {
+			continue
+		}
+		switch {
+		case healthy:
+			log.Info("RPC节点已恢复", "endpoint", ep.Name, "slot", r.slot)
+		case r.err != nil:
+			log.Warn("RPC节点健康检查失败，已移出轮询", "endpoint", ep.Name, "error", r.err)
+		default:
+			log.Warn("RPC节点落后过多，已移出轮询", "endpoint", ep.Name, "slot", r.slot, "best_slot", best)
+		}
+	}
+}
+
+// healthLoop 按照间隔执行健康检查直到客户端关闭
+func (c *Client) healthLoop() {
+	ticker := time.NewTicker(c.health.Interval)
+	defer ticker.Stop()
+
+	for {
+		c.CheckHealth(c.ctx)
+		select {
+		case <-c.ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
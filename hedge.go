@@ -0,0 +1,172 @@
+package gosolana
+
+import (
+	"context"
+	"encoding/json"
+	"slices"
+	"sync"
+	"time"
+)
+
+// DefaultHedgeMethods 默认允许对冲的只读方法
+var DefaultHedgeMethods = []string{
+	"getAccountInfo",
+	"getBalance",
+	"getBlockHeight",
+	"getLatestBlockhash",
+	"getMultipleAccounts",
+	"getSignatureStatuses",
+	"getSlot",
+	"getTokenAccountBalance",
+	"isBlockhashValid",
+}
+
+// HedgePolicy 对冲请求配置
+//
+// 第一个节点在对冲延迟内没有返回时，同样的请求会再发送到另一个节点，先返回成功结果的请求胜出，另一个请求会被取消。
+// 对冲延迟为该方法最近请求延迟的 Percentile 百分位，并限制在 [MinDelay, MaxDelay] 之间，样本不足时使用 MaxDelay
+type HedgePolicy struct {
+	Methods    []string      // 允许对冲的方法，默认为 DefaultHedgeMethods
+	Percentile float64       // 默认0.95
+	MinDelay   time.Duration // 默认10毫秒
+	MaxDelay   time.Duration // 默认500毫秒
+
+	// SendTransaction 是否允许对 sendTransaction 对冲，需要同时在 Methods 中包含 sendTransaction，
+	// 只满足其中一个条件时不会对冲
+	SendTransaction bool
+}
+
+// WithHedging 开启对冲请求，只对 CallForInto 发起的请求生效
+func WithHedging(policy HedgePolicy) ClientOption {
+	return func(ctx context.Context, client *Client) {
+		if len(policy.Methods) == 0 {
+			policy.Methods = DefaultHedgeMethods
+		}
+		if policy.Percentile <= 0 || policy.Percentile > 1 {
+			policy.Percentile = 0.95
+		}
+		if policy.MinDelay <= 0 {
+			policy.MinDelay = 10 * time.Millisecond
+		}
+		if policy.MaxDelay <= 0 {
+			policy.MaxDelay = 500 * time.Millisecond
+		}
+		policy.MaxDelay = max(policy.MaxDelay, policy.MinDelay)
+		client.hedge = &policy
+		client.latency = &latencyTracker{samples: map[string]*latencyRing{}}
+	}
+}
+
+func (p *HedgePolicy) applies(method string) bool {
+	if method == "sendTransaction" && !p.SendTransaction {
+		return false
+	}
+	return slices.Contains(p.Methods, method)
+}
+
+// hedged 对冲执行 CallForInto
+func (c *Client) hedged(ctx context.Context, endpoints []*Endpoint, out interface{}, method string, params []interface{}) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	type result struct {
+		raw json.RawMessage
+		err error
+	}
+	var (
+		tried   = &triedSet{}
+		results = make(chan result, 2)
+	)
+	run := func() {
+		var raw json.RawMessage
+		err := c.attempt(ctx, endpoints, tried, func(ep *Endpoint) error {
+			// 每次请求单独记录延迟，不包括等待对冲的时间和之前失败的重试
+			start := time.Now()
+			err := ep.rpc.RPCCallForInto(ctx, &raw, method, params)
+			if err == nil {
+				c.latency.observe(method, time.Since(start))
+			}
+			return err
+		})
+		results <- result{raw: raw, err: err}
+	}
+
+	go run()
+	timer := time.NewTimer(c.latency.delay(method, c.hedge))
+	defer timer.Stop()
+
+	var (
+		pending  = 1
+		hedging  = false
+		firstErr error
+	)
+	for {
+		select {
+		case <-timer.C:
+			hedging = true
+			pending++
+			go run()
+		case r := <-results:
+			pending--
+			if r.err == nil {
+				return json.Unmarshal(r.raw, out)
+			}
+			if firstErr == nil {
+				firstErr = r.err
+			}
+			// 对冲请求还没有发出时第一个请求已经经过了重试，直接返回错误
+			if pending == 0 || !hedging {
+				return firstErr
+			}
+		}
+	}
+}
+
+const (
+	latencySamples    = 256
+	latencyMinSamples = 20
+)
+
+// latencyTracker 记录每个方法最近的请求延迟
+type latencyTracker struct {
+	lock    sync.Mutex
+	samples map[string]*latencyRing
+}
+
+type latencyRing struct {
+	values [latencySamples]time.Duration
+	count  int
+	next   int
+}
+
+func (t *latencyTracker) observe(method string, d time.Duration) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	ring, ok := t.samples[method]
+	if !ok {
+		ring = new(latencyRing)
+		t.samples[method] = ring
+	}
+	ring.values[ring.next] = d
+	ring.next = (ring.next + 1) % latencySamples
+	ring.count = min(ring.count+1, latencySamples)
+}
+
+// delay 计算方法的对冲延迟
+func (t *latencyTracker) delay(method string, policy *HedgePolicy) time.Duration {
+	t.lock.Lock()
+	ring, ok := t.samples[method]
+	var values []time.Duration
+	if ok && ring.count >= latencyMinSamples {
+		values = slices.Clone(ring.values[:ring.count])
+	}
+	t.lock.Unlock()
+
+	if values == nil {
+		return policy.MaxDelay
+	}
+	slices.Sort(values)
+	d := values[min(int(float64(len(values))*policy.Percentile), len(values)-1)]
+	return min(max(d, policy.MinDelay), policy.MaxDelay)
+}
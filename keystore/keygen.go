@@ -0,0 +1,74 @@
+package keystore
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/gagliardetto/solana-go"
+)
+
+// ReadKeygenFile 读取 Solana CLI（solana-keygen）生成的 id.json，文件内容为64个字节的数组
+func ReadKeygenFile(path string) (solana.PrivateKey, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return parseKeygen(data)
+}
+
+// WriteKeygenFile 以 Solana CLI 的 id.json 格式写入未加密的私钥，文件权限为0600，不会覆盖已有的文件
+func WriteKeygenFile(path string, key solana.PrivateKey) error {
+	if err := validateKey(key); err != nil {
+		return err
+	}
+	values := make([]int, len(key))
+	for i, b := range key {
+		values[i] = int(b)
+	}
+	data, err := json.Marshal(values)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return err
+	}
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o600)
+	if err != nil {
+		return err
+	}
+	if _, err := file.Write(data); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+func parseKeygen(data []byte) (solana.PrivateKey, error) {
+	var values []byte
+	if err := json.Unmarshal(bytes.TrimSpace(data), &values); err != nil {
+		return nil, fmt.Errorf("解析id.json失败: %w", err)
+	}
+	key := solana.PrivateKey(values)
+	if err := validateKey(key); err != nil {
+		return nil, err
+	}
+	return key, nil
+}
+
+// validateKey 校验私钥长度以及后32个字节是否为私钥对应的公钥
+func validateKey(key solana.PrivateKey) error {
+	if len(key) != ed25519.PrivateKeySize {
+		return fmt.Errorf("私钥长度应该为 %d 个字节，实际为 %d 个字节", ed25519.PrivateKeySize, len(key))
+	}
+	expected := ed25519.NewKeyFromSeed(key[:ed25519.SeedSize])
+	if !bytes.Equal(expected[ed25519.SeedSize:], key[ed25519.SeedSize:]) {
+		return errors.New("私钥与公钥不匹配")
+	}
+	return nil
+}
@@ -0,0 +1,309 @@
+// Package keystore 使用口令加密保存 ed25519 私钥，并兼容 Solana CLI 的 id.json 格式
+//
+// 加密文件为带版本号的JSON，与以太坊的 keystore 类似：使用 scrypt 或者 argon2id 从口令派生密钥，
+// 再使用 AES-256-GCM 加密私钥，钱包地址作为附加数据参与认证
+package keystore
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/gagliardetto/solana-go"
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/scrypt"
+)
+
+// Version 当前的文件格式版本
+const Version = 1
+
+// KDF 从口令派生密钥的算法
+type KDF string
+
+const (
+	KDFScrypt   KDF = "scrypt"
+	KDFArgon2id KDF = "argon2id"
+)
+
+const (
+	cipherAES256GCM = "aes-256-gcm"
+	keyLength       = 32
+	saltLength      = 32
+)
+
+// 解密时允许的密钥派生参数上限，为默认参数的4倍左右，防止被篡改的文件让解密消耗大量的内存和时间
+const (
+	maxScryptN       = 1 << 20
+	maxScryptR       = 32
+	maxScryptP       = 16
+	maxScryptMemory  = 1 << 30 // 128*N*r 字节
+	maxArgon2Time    = 12
+	maxArgon2Memory  = 256 * 1024 // 单位KiB
+	maxArgon2Threads = 16
+)
+
+var (
+	// ErrDecrypt 口令错误或者文件被篡改
+	ErrDecrypt = errors.New("无法解密私钥，口令错误或者文件已损坏")
+	// ErrVersion 不支持的文件版本
+	ErrVersion = errors.New("不支持的keystore版本")
+	// ErrKDFParams 密钥派生参数无效或者超过上限
+	ErrKDFParams = errors.New("无效的密钥派生参数")
+)
+
+// Options 加密参数，零值字段使用 DefaultOptions 中的值
+type Options struct {
+	KDF KDF
+
+	ScryptN int
+	ScryptR int
+	ScryptP int
+
+	Argon2Time    uint32
+	Argon2Memory  uint32 // 单位KiB
+	Argon2Threads uint8
+}
+
+// DefaultOptions 默认的加密参数，解密一次大约需要几百毫秒
+var DefaultOptions = Options{
+	KDF:           KDFScrypt,
+	ScryptN:       1 << 18,
+	ScryptR:       8,
+	ScryptP:       1,
+	Argon2Time:    3,
+	Argon2Memory:  64 * 1024,
+	Argon2Threads: 4,
+}
+
+// LightOptions 计算量较小的加密参数，只适用于测试或者低性能的设备
+var LightOptions = Options{
+	KDF:           KDFScrypt,
+	ScryptN:       1 << 12,
+	ScryptR:       8,
+	ScryptP:       6,
+	Argon2Time:    1,
+	Argon2Memory:  4 * 1024,
+	Argon2Threads: 1,
+}
+
+// File 加密文件的内容
+type File struct {
+	Version int    `json:"version"`
+	Address string `json:"address"`
+	Crypto  Crypto `json:"crypto"`
+}
+
+// Crypto 加密算法和参数
+type Crypto struct {
+	Cipher     string    `json:"cipher"`
+	CipherText string    `json:"ciphertext"`
+	Nonce      string    `json:"nonce"`
+	KDF        KDF       `json:"kdf"`
+	KDFParams  KDFParams `json:"kdfparams"`
+}
+
+// KDFParams 派生密钥的参数，只有对应算法的字段有值
+type KDFParams struct {
+	Salt string `json:"salt"`
+
+	N int `json:"n,omitempty"`
+	R int `json:"r,omitempty"`
+	P int `json:"p,omitempty"`
+
+	Time    uint32 `json:"time,omitempty"`
+	Memory  uint32 `json:"memory,omitempty"`
+	Threads uint8  `json:"threads,omitempty"`
+}
+
+// Encrypt 使用口令加密私钥，返回JSON格式的keystore
+func Encrypt(key solana.PrivateKey, passphrase string, opts ...Options) ([]byte, error) {
+	if err := validateKey(key); err != nil {
+		return nil, fmt.Errorf("无效的私钥: %w", err)
+	}
+	opt := DefaultOptions
+	if len(opts) > 0 {
+		opt = withDefaults(opts[0])
+	}
+
+	salt := make([]byte, saltLength)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, err
+	}
+	params := KDFParams{Salt: hex.EncodeToString(salt)}
+	switch opt.KDF {
+	case KDFScrypt:
+		params.N, params.R, params.P = opt.ScryptN, opt.ScryptR, opt.ScryptP
+	case KDFArgon2id:
+		params.Time, params.Memory, params.Threads = opt.Argon2Time, opt.Argon2Memory, opt.Argon2Threads
+	default:
+		return nil, fmt.Errorf("不支持的密钥派生算法 %q", opt.KDF)
+	}
+	derived, err := deriveKey(opt.KDF, params, passphrase)
+	if err != nil {
+		return nil, err
+	}
+	defer clear(derived)
+
+	address := key.PublicKey().String()
+	aead, err := newGCM(derived)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+
+	return json.MarshalIndent(File{
+		Version: Version,
+		Address: address,
+		Crypto: Crypto{
+			Cipher:     cipherAES256GCM,
+			CipherText: hex.EncodeToString(aead.Seal(nil, nonce, key, []byte(address))),
+			Nonce:      hex.EncodeToString(nonce),
+			KDF:        opt.KDF,
+			KDFParams:  params,
+		},
+	}, "", "  ")
+}
+
+// Decrypt 使用口令解密JSON格式的keystore，口令错误时返回 ErrDecrypt
+func Decrypt(data []byte, passphrase string) (solana.PrivateKey, error) {
+	var file File
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("解析keystore失败: %w", err)
+	}
+	if file.Version != Version {
+		return nil, fmt.Errorf("%w: %d", ErrVersion, file.Version)
+	}
+	if file.Crypto.Cipher != cipherAES256GCM {
+		return nil, fmt.Errorf("不支持的加密算法 %q", file.Crypto.Cipher)
+	}
+	cipherText, err := hex.DecodeString(file.Crypto.CipherText)
+	if err != nil {
+		return nil, fmt.Errorf("解析密文失败: %w", err)
+	}
+	nonce, err := hex.DecodeString(file.Crypto.Nonce)
+	if err != nil {
+		return nil, fmt.Errorf("解析nonce失败: %w", err)
+	}
+
+	derived, err := deriveKey(file.Crypto.KDF, file.Crypto.KDFParams, passphrase)
+	if err != nil {
+		return nil, err
+	}
+	defer clear(derived)
+	aead, err := newGCM(derived)
+	if err != nil {
+		return nil, err
+	}
+	if len(nonce) != aead.NonceSize() {
+		return nil, ErrDecrypt
+	}
+	plain, err := aead.Open(nil, nonce, cipherText, []byte(file.Address))
+	if err != nil {
+		return nil, ErrDecrypt
+	}
+
+	key := solana.PrivateKey(plain)
+	if validateKey(key) != nil || key.PublicKey().String() != file.Address {
+		return nil, ErrDecrypt
+	}
+	return key, nil
+}
+
+// ReadFile 读取并解密keystore文件
+func ReadFile(path, passphrase string) (solana.PrivateKey, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return Decrypt(data, passphrase)
+}
+
+// WriteFile 加密私钥并写入文件，文件权限为0600
+func WriteFile(path string, key solana.PrivateKey, passphrase string, opts ...Options) error {
+	data, err := Encrypt(key, passphrase, opts...)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0o600)
+}
+
+// Load 读取私钥文件，自动识别加密的keystore和 Solana CLI 的 id.json 格式，id.json 不需要口令
+func Load(path, passphrase string) (solana.PrivateKey, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	if trimmed := bytes.TrimSpace(data); len(trimmed) > 0 && trimmed[0] == '[' {
+		return parseKeygen(trimmed)
+	}
+	return Decrypt(data, passphrase)
+}
+
+func withDefaults(opt Options) Options {
+	if opt.KDF == "" {
+		opt.KDF = DefaultOptions.KDF
+	}
+	if opt.ScryptN == 0 {
+		opt.ScryptN = DefaultOptions.ScryptN
+	}
+	if opt.ScryptR == 0 {
+		opt.ScryptR = DefaultOptions.ScryptR
+	}
+	if opt.ScryptP == 0 {
+		opt.ScryptP = DefaultOptions.ScryptP
+	}
+	if opt.Argon2Time == 0 {
+		opt.Argon2Time = DefaultOptions.Argon2Time
+	}
+	if opt.Argon2Memory == 0 {
+		opt.Argon2Memory = DefaultOptions.Argon2Memory
+	}
+	if opt.Argon2Threads == 0 {
+		opt.Argon2Threads = DefaultOptions.Argon2Threads
+	}
+	return opt
+}
+
+func deriveKey(kdf KDF, params KDFParams, passphrase string) ([]byte, error) {
+	salt, err := hex.DecodeString(params.Salt)
+	if err != nil || len(salt) == 0 {
+		return nil, errors.New("无效的salt")
+	}
+	switch kdf {
+	case KDFScrypt:
+		if params.N <= 1 || params.R <= 0 || params.P <= 0 ||
+			params.N > maxScryptN || params.R > maxScryptR || params.P > maxScryptP || 128*int64(params.N)*int64(params.R) > maxScryptMemory {
+			return nil, fmt.Errorf("%w: scrypt N=%d r=%d p=%d", ErrKDFParams, params.N, params.R, params.P)
+		}
+		key, err := scrypt.Key([]byte(passphrase), salt, params.N, params.R, params.P, keyLength)
+		if err != nil {
+			return nil, fmt.Errorf("scrypt派生密钥失败: %w", err)
+		}
+		return key, nil
+	case KDFArgon2id:
+		if params.Time == 0 || params.Memory == 0 || params.Threads == 0 ||
+			params.Time > maxArgon2Time || params.Memory > maxArgon2Memory || params.Threads > maxArgon2Threads {
+			return nil, fmt.Errorf("%w: argon2id time=%d memory=%d threads=%d", ErrKDFParams, params.Time, params.Memory, params.Threads)
+		}
+		return argon2.IDKey([]byte(passphrase), salt, params.Time, params.Memory, params.Threads, keyLength), nil
+	default:
+		return nil, fmt.Errorf("不支持的密钥派生算法 %q", kdf)
+	}
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
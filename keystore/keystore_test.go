@@ -0,0 +1,94 @@
+package keystore
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/gagliardetto/solana-go"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_EncryptDecrypt(t *testing.T) {
+	key := solana.NewWallet().PrivateKey
+	for _, kdf := range []KDF{KDFScrypt, KDFArgon2id} {
+		opts := LightOptions
+		opts.KDF = kdf
+		data, err := Encrypt(key, "passphrase", opts)
+		require.NoError(t, err)
+		require.NotContains(t, string(data), key.String())
+		require.Contains(t, string(data), key.PublicKey().String())
+
+		decrypted, err := Decrypt(data, "passphrase")
+		require.NoError(t, err, kdf)
+		require.Equal(t, key, decrypted)
+
+		_, err = Decrypt(data, "wrong")
+		require.ErrorIs(t, err, ErrDecrypt)
+	}
+}
+
+func Test_KeystoreFiles(t *testing.T) {
+	dir := t.TempDir()
+	key := solana.NewWallet().PrivateKey
+
+	encrypted := filepath.Join(dir, "wallet.json")
+	require.NoError(t, WriteFile(encrypted, key, "passphrase", LightOptions))
+	info, err := os.Stat(encrypted)
+	require.NoError(t, err)
+	require.Equal(t, os.FileMode(0o600), info.Mode().Perm())
+
+	keygen := filepath.Join(dir, "id.json")
+	require.NoError(t, WriteKeygenFile(keygen, key))
+	require.Error(t, WriteKeygenFile(keygen, key), "不应该覆盖已有的文件")
+
+	// 与 solana-go 读取 id.json 的结果一致
+	expected, err := solana.PrivateKeyFromSolanaKeygenFile(keygen)
+	require.NoError(t, err)
+	require.Equal(t, key, expected)
+
+	for _, path := range []string{encrypted, keygen} {
+		loaded, err := Load(path, "passphrase")
+		require.NoError(t, err)
+		require.Equal(t, key, loaded)
+	}
+
+	require.NoError(t, os.WriteFile(keygen, []byte("[1, 2, 3]"), 0o600))
+	_, err = ReadKeygenFile(keygen)
+	require.Error(t, err)
+}
+
+func Test_DecryptRejectsExpensiveKDF(t *testing.T) {
+	key := solana.NewWallet().PrivateKey
+	cases := []struct {
+		kdf    KDF
+		tamper func(*KDFParams)
+	}{
+		{KDFScrypt, func(p *KDFParams) { p.N = 1 << 30 }},
+		{KDFScrypt, func(p *KDFParams) { p.R = 1 << 10 }},
+		{KDFScrypt, func(p *KDFParams) { p.P = 1 << 10 }},
+		{KDFScrypt, func(p *KDFParams) { p.N, p.R = maxScryptN, maxScryptR }},
+		{KDFArgon2id, func(p *KDFParams) { p.Time = 1 << 20 }},
+		{KDFArgon2id, func(p *KDFParams) { p.Memory = 1 << 30 }},
+		{KDFArgon2id, func(p *KDFParams) { p.Threads = 255 }},
+	}
+	for _, c := range cases {
+		opts := LightOptions
+		opts.KDF = c.kdf
+		data, err := Encrypt(key, "passphrase", opts)
+		require.NoError(t, err)
+
+		// 篡改的参数不应该被执行，否则解密会消耗大量的内存和时间
+		var file File
+		require.NoError(t, json.Unmarshal(data, &file))
+		c.tamper(&file.Crypto.KDFParams)
+		data, err = json.Marshal(file)
+		require.NoError(t, err)
+		_, err = Decrypt(data, "passphrase")
+		require.ErrorIs(t, err, ErrKDFParams, file.Crypto.KDFParams)
+	}
+
+	_, err := Encrypt(key, "passphrase", Options{KDF: KDFArgon2id, Argon2Memory: 1 << 30})
+	require.ErrorIs(t, err, ErrKDFParams)
+}
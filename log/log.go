@@ -0,0 +1,42 @@
+// Package log gosolana 和 ws 包共用的日志，基于 log/slog
+//
+// 默认输出到标准错误，级别为 Info。通过 SetLogger 可以替换为任意 slog.Handler，
+// 所有日志在交给 Handler 之前都会经过 RedactHandler 隐藏私钥、助记词等敏感信息
+package log
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"os"
+	"sync/atomic"
+)
+
+var logger atomic.Pointer[slog.Logger]
+
+func init() {
+	SetLogger(slog.New(slog.NewTextHandler(os.Stderr, nil)))
+}
+
+// SetLogger 替换全局日志，handler 会被 RedactHandler 包装，传入 nil 时丢弃所有日志
+func SetLogger(l *slog.Logger) {
+	if l == nil {
+		l = slog.New(slog.NewTextHandler(io.Discard, nil))
+	}
+	logger.Store(slog.New(NewRedactHandler(l.Handler())))
+}
+
+// Logger 返回当前的全局日志
+func Logger() *slog.Logger {
+	return logger.Load()
+}
+
+// Enabled 指定级别的日志是否会被输出
+func Enabled(level slog.Level) bool {
+	return Logger().Enabled(context.Background(), level)
+}
+
+func Debug(msg string, args ...any) { Logger().Debug(msg, args...) }
+func Info(msg string, args ...any)  { Logger().Info(msg, args...) }
+func Warn(msg string, args ...any)  { Logger().Warn(msg, args...) }
+func Error(msg string, args ...any) { Logger().Error(msg, args...) }
@@ -0,0 +1,142 @@
+package log
+
+import (
+	"bytes"
+	"context"
+	"crypto/ed25519"
+	"fmt"
+	"log/slog"
+	"regexp"
+	"strings"
+
+	"github.com/gagliardetto/solana-go"
+	"github.com/mr-tron/base58"
+)
+
+// Redacted 替换敏感信息的文本
+const Redacted = "[REDACTED]"
+
+// sensitiveKeys 属性名包含这些词时属性值会被隐藏，不区分大小写
+var sensitiveKeys = []string{
+	"private",
+	"pkey",
+	"secret",
+	"mnemonic",
+	"passphrase",
+	"password",
+	"seed",
+	"apikey",
+	"api_key",
+	"api-key",
+	"authorization",
+}
+
+// base58KeyPattern 可能是base58编码的64字节私钥的文本
+var base58KeyPattern = regexp.MustCompile(`[1-9A-HJ-NP-Za-km-z]{85,90}`)
+
+// RedactHandler 隐藏敏感信息的 slog.Handler
+//
+// 以下内容会被替换为 Redacted：
+//   - 名称包含 private、secret、mnemonic、password 等词的属性
+//   - solana.PrivateKey 和 solana.Wallet 类型的属性值
+//   - 日志消息、字符串和错误中base58编码的私钥，同样是64字节的交易签名不会被隐藏
+type RedactHandler struct {
+	next slog.Handler
+}
+
+// NewRedactHandler 包装 handler，已经包装过的 handler 会直接返回
+func NewRedactHandler(next slog.Handler) slog.Handler {
+	if h, ok := next.(*RedactHandler); ok {
+		return h
+	}
+	return &RedactHandler{next: next}
+}
+
+func (h *RedactHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.next.Enabled(ctx, level)
+}
+
+func (h *RedactHandler) Handle(ctx context.Context, record slog.Record) error {
+	redacted := slog.NewRecord(record.Time, record.Level, RedactString(record.Message), record.PC)
+	record.Attrs(func(attr slog.Attr) bool {
+		redacted.AddAttrs(redactAttr(attr))
+		return true
+	})
+	return h.next.Handle(ctx, redacted)
+}
+
+func (h *RedactHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	redacted := make([]slog.Attr, len(attrs))
+	for i, attr := range attrs {
+		redacted[i] = redactAttr(attr)
+	}
+	return &RedactHandler{next: h.next.WithAttrs(redacted)}
+}
+
+func (h *RedactHandler) WithGroup(name string) slog.Handler {
+	return &RedactHandler{next: h.next.WithGroup(name)}
+}
+
+func redactAttr(attr slog.Attr) slog.Attr {
+	if sensitiveKey(attr.Key) {
+		return slog.String(attr.Key, Redacted)
+	}
+	value := attr.Value.Resolve()
+	switch value.Kind() {
+	case slog.KindString:
+		return slog.String(attr.Key, RedactString(value.String()))
+	case slog.KindGroup:
+		group := value.Group()
+		redacted := make([]any, len(group))
+		for i, a := range group {
+			redacted[i] = redactAttr(a)
+		}
+		return slog.Group(attr.Key, redacted...)
+	case slog.KindAny:
+		switch v := value.Any().(type) {
+		case solana.PrivateKey, *solana.PrivateKey, solana.Wallet, *solana.Wallet:
+			return slog.String(attr.Key, Redacted)
+		case error:
+			if s := v.Error(); RedactString(s) != s {
+				return slog.String(attr.Key, RedactString(s))
+			}
+		case fmt.Stringer:
+			if s := v.String(); RedactString(s) != s {
+				return slog.String(attr.Key, RedactString(s))
+			}
+		}
+	}
+	return slog.Attr{Key: attr.Key, Value: value}
+}
+
+func sensitiveKey(key string) bool {
+	key = strings.ToLower(key)
+	for _, word := range sensitiveKeys {
+		if strings.Contains(key, word) {
+			return true
+		}
+	}
+	return false
+}
+
+// RedactString 隐藏文本中base58编码的私钥
+func RedactString(s string) string {
+	if len(s) < 85 {
+		return s
+	}
+	return base58KeyPattern.ReplaceAllStringFunc(s, func(match string) string {
+		if raw, err := base58.Decode(match); err == nil && isPrivateKey(raw) {
+			return Redacted
+		}
+		return match
+	})
+}
+
+// isPrivateKey 私钥的后32个字节是前32个字节对应的公钥，以此区分私钥和交易签名
+func isPrivateKey(raw []byte) bool {
+	if len(raw) != ed25519.PrivateKeySize {
+		return false
+	}
+	expected := ed25519.NewKeyFromSeed(raw[:ed25519.SeedSize])
+	return bytes.Equal(expected[ed25519.SeedSize:], raw[ed25519.SeedSize:])
+}
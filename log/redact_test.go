@@ -0,0 +1,57 @@
+package log
+
+import (
+	"bytes"
+	"errors"
+	"log/slog"
+	"testing"
+
+	"github.com/gagliardetto/solana-go"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_RedactHandler(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(NewRedactHandler(slog.NewTextHandler(&buf, nil)))
+	key := solana.NewWallet().PrivateKey
+
+	logger.Info("私钥 "+key.String(),
+		"key", key,
+		"wallet_pkey", "anything",
+		"raw", key.String(),
+		"error", errors.New("invalid key "+key.String()),
+		slog.Group("auth", "password", "hunter2"),
+		"address", key.PublicKey(),
+	)
+	out := buf.String()
+	require.NotContains(t, out, key.String())
+	require.NotContains(t, out, "anything")
+	require.NotContains(t, out, "hunter2")
+	require.Contains(t, out, key.PublicKey().String(), "公钥不应该被隐藏")
+	require.Contains(t, out, Redacted)
+
+	buf.Reset()
+	logger.With("private_key", key.String()).WithGroup("g").Info("with", "signature", solana.Signature{1})
+	require.NotContains(t, buf.String(), key.String())
+
+	buf.Reset()
+	signature, err := key.Sign([]byte("message"))
+	require.NoError(t, err)
+	logger.Info("交易已发送 "+signature.String(), "signature", signature.String())
+	require.NotContains(t, buf.String(), Redacted, "交易签名不应该被隐藏")
+}
+
+func Test_SetLogger(t *testing.T) {
+	defer SetLogger(Logger())
+
+	var buf bytes.Buffer
+	SetLogger(slog.New(slog.NewTextHandler(&buf, nil)))
+	_, ok := Logger().Handler().(*RedactHandler)
+	require.True(t, ok, "SetLogger 应该默认隐藏敏感信息")
+
+	Info("test", "seed", "abandon abandon")
+	require.NotContains(t, buf.String(), "abandon")
+
+	SetLogger(nil)
+	Info("discarded")
+}
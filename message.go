@@ -0,0 +1,120 @@
+package gosolana
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"unicode/utf8"
+
+	"github.com/gagliardetto/solana-go"
+)
+
+// OffchainSigningDomain 链下消息的签名域，保证链下消息的签名不会被当作交易签名使用
+const OffchainSigningDomain = "\xffsolana offchain"
+
+// OffchainMessageVersion 支持的链下消息版本
+const OffchainMessageVersion = 0
+
+const (
+	offchainHeaderLength = len(OffchainSigningDomain) + 1 + 1 + 2 // 签名域、版本、格式、长度
+	// MaxOffchainMessageLength 链下消息的最大长度
+	MaxOffchainMessageLength = 65535 - offchainHeaderLength
+	// MaxOffchainMessageLengthLedger Ledger 硬件钱包可以显示的最大长度
+	MaxOffchainMessageLengthLedger = 1232 - offchainHeaderLength
+)
+
+// MessageFormat 链下消息的格式
+type MessageFormat uint8
+
+const (
+	MessageFormatRestrictedASCII MessageFormat = iota // 可打印ASCII字符，不超过 MaxOffchainMessageLengthLedger
+	MessageFormatLimitedUTF8                          // UTF-8文本，不超过 MaxOffchainMessageLengthLedger
+	MessageFormatExtendedUTF8                         // UTF-8文本，不超过 MaxOffchainMessageLength
+)
+
+// MessageEncoding 消息签名的方式
+type MessageEncoding int
+
+const (
+	// MessageOffchain 按照 Solana 链下消息格式签名，与 solana sign-offchain-message 兼容
+	MessageOffchain MessageEncoding = iota
+	// MessagePlain 直接对原始字节签名，用于 Sign in with Solana 等钱包登录流程
+	MessagePlain
+)
+
+var (
+	// ErrInvalidSignature 签名与消息或者地址不匹配
+	ErrInvalidSignature = errors.New("签名无效")
+	// ErrInvalidMessage 消息为空、过长或者不是有效的UTF-8文本
+	ErrInvalidMessage = errors.New("无效的链下消息")
+)
+
+// EncodeOffchainMessage 为消息加上链下消息头：签名域、版本、格式和小端序的消息长度，格式根据内容自动选择
+func EncodeOffchainMessage(message []byte) ([]byte, error) {
+	format, err := offchainFormat(message)
+	if err != nil {
+		return nil, err
+	}
+	out := bytes.NewBuffer(make([]byte, 0, offchainHeaderLength+len(message)))
+	out.WriteString(OffchainSigningDomain)
+	out.WriteByte(OffchainMessageVersion)
+	out.WriteByte(byte(format))
+	binary.Write(out, binary.LittleEndian, uint16(len(message)))
+	out.Write(message)
+	return out.Bytes(), nil
+}
+
+func offchainFormat(message []byte) (MessageFormat, error) {
+	switch {
+	case len(message) == 0:
+		return 0, fmt.Errorf("%w: 消息不能为空", ErrInvalidMessage)
+	case len(message) > MaxOffchainMessageLength:
+		return 0, fmt.Errorf("%w: 消息长度 %d 超过了 %d", ErrInvalidMessage, len(message), MaxOffchainMessageLength)
+	case !utf8.Valid(message):
+		return 0, fmt.Errorf("%w: 消息不是有效的UTF-8文本", ErrInvalidMessage)
+	case len(message) > MaxOffchainMessageLengthLedger:
+		return MessageFormatExtendedUTF8, nil
+	}
+	for _, b := range message {
+		if b < 0x20 || b > 0x7e {
+			return MessageFormatLimitedUTF8, nil
+		}
+	}
+	return MessageFormatRestrictedASCII, nil
+}
+
+// encodeMessage 返回实际签名的字节
+func encodeMessage(message []byte, encoding []MessageEncoding) ([]byte, error) {
+	if len(encoding) > 0 && encoding[0] == MessagePlain {
+		return message, nil
+	}
+	return EncodeOffchainMessage(message)
+}
+
+// SignMessage 使用钱包签名链下消息，默认使用 MessageOffchain 格式
+func (w *Wallet) SignMessage(ctx context.Context, message []byte, encoding ...MessageEncoding) (solana.Signature, error) {
+	data, err := encodeMessage(message, encoding)
+	if err != nil {
+		return solana.Signature{}, err
+	}
+	return w.signer.SignMessage(ctx, data)
+}
+
+// VerifyMessage 校验钱包对消息的签名，见 VerifyMessage
+func (w *Wallet) VerifyMessage(message []byte, signature solana.Signature, encoding ...MessageEncoding) error {
+	return VerifyMessage(w.PublicKey(), message, signature, encoding...)
+}
+
+// VerifyMessage 校验 publicKey 对消息的签名，例如用户登录时钱包返回的签名，签名无效时返回 ErrInvalidSignature
+func VerifyMessage(publicKey solana.PublicKey, message []byte, signature solana.Signature, encoding ...MessageEncoding) error {
+	data, err := encodeMessage(message, encoding)
+	if err != nil {
+		return err
+	}
+	if !signature.Verify(publicKey, data) {
+		return ErrInvalidSignature
+	}
+	return nil
+}
@@ -0,0 +1,53 @@
+package gosolana
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/gagliardetto/solana-go"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_EncodeOffchainMessage(t *testing.T) {
+	encoded, err := EncodeOffchainMessage([]byte("Hello"))
+	require.NoError(t, err)
+	require.Equal(t, append([]byte("\xffsolana offchain\x00\x00\x05\x00"), "Hello"...), encoded)
+
+	formats := map[string]MessageFormat{
+		"Hello":                   MessageFormatRestrictedASCII,
+		"你好":                      MessageFormatLimitedUTF8,
+		"line\nbreak":             MessageFormatLimitedUTF8,
+		strings.Repeat("a", 2000): MessageFormatExtendedUTF8,
+	}
+	for message, format := range formats {
+		encoded, err := EncodeOffchainMessage([]byte(message))
+		require.NoError(t, err)
+		require.Equal(t, byte(format), encoded[17], message)
+	}
+
+	for _, message := range [][]byte{nil, {0xff, 0xfe}, make([]byte, MaxOffchainMessageLength+1)} {
+		_, err := EncodeOffchainMessage(message)
+		require.ErrorIs(t, err, ErrInvalidMessage)
+	}
+}
+
+func Test_SignMessage(t *testing.T) {
+	ctx := context.Background()
+	node := newTestNode(t, 1)
+	wallet, err := NewWallet(ctx, Option{RpcUrl: node.URL, WsMode: WsDisabled})
+	require.NoError(t, err)
+	message := []byte("example.com wants you to sign in with your Solana account")
+
+	signature, err := wallet.SignMessage(ctx, message)
+	require.NoError(t, err)
+	require.NoError(t, wallet.VerifyMessage(message, signature))
+	require.ErrorIs(t, VerifyMessage(wallet.PublicKey(), message, signature, MessagePlain), ErrInvalidSignature,
+		"链下消息的签名不能当作原始字节的签名")
+	require.ErrorIs(t, VerifyMessage(solana.NewWallet().PublicKey(), message, signature), ErrInvalidSignature)
+
+	plain, err := wallet.SignMessage(ctx, message, MessagePlain)
+	require.NoError(t, err)
+	require.NoError(t, VerifyMessage(wallet.PublicKey(), message, plain, MessagePlain))
+	require.ErrorIs(t, wallet.VerifyMessage(append(message, '!'), plain, MessagePlain), ErrInvalidSignature)
+}
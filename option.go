@@ -2,11 +2,17 @@ package gosolana
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"log/slog"
 	"net/http"
 	"net/url"
+	"slices"
+	"strings"
 	"time"
 
+	"github.com/go-enols/gosolana/hd"
+	"github.com/go-enols/gosolana/log"
 	"github.com/go-enols/gosolana/ws"
 
 	"github.com/gagliardetto/solana-go"
@@ -21,36 +27,114 @@ type Option struct {
 	WsUrl         string
 	RpcUrl        string
 	Pkey          string
-	Headers       map[string]string
-	HTTPClient    *http.Client
-	Proxy         string
-	WsProxy       string
-	TimeOut       time.Duration
+	Signer        Signer // 钱包的签名者，例如远程签名服务，设置后不能再设置 Pkey
+
+	// Keystore 私钥文件路径，支持 keystore 包加密的文件和 Solana CLI 的 id.json，不能与 Pkey 或者 Signer 同时设置
+	Keystore string
+	// KeystorePassphrase 加密私钥文件的口令，id.json 不需要口令
+	KeystorePassphrase string
+
+	// Mnemonic BIP39 助记词，与 Phantom、Solflare 一样使用 m/44'/501'/{Account}'/0' 派生私钥，见 hd 包
+	Mnemonic string
+	// MnemonicPassphrase 助记词的口令（BIP39 的第25个单词），没有时为空
+	MnemonicPassphrase string
+	// Account 从助记词派生第几个账户
+	Account uint32
+	// DerivationPath 自定义派生路径，设置后忽略 Account
+	DerivationPath string
+	Headers        map[string]string
+	HTTPClient     *http.Client
+	Proxy          string
+	WsProxy        string
+	TimeOut        time.Duration
+	RateLimit      int    // RpcUrl 每秒最多请求的次数，0表示不限制
+	WsMode         WsMode // ws连接方式，默认创建时立即连接
+
+	// Cluster 集群名称，见 LookupCluster，RpcUrl 和 WsUrl 为空时使用集群的地址
+	Cluster string
+	// VerifyGenesis 创建钱包时校验节点的创世哈希，不一致时 NewWallet 返回 ErrGenesisMismatch
+	VerifyGenesis bool
+	// GenesisHash 期望的创世哈希，为空时使用 Cluster 的创世哈希
+	GenesisHash string
+
+	// RpcOptions 额外的RPC节点、重试策略等配置
+	//
+	// 设置后 RpcUrl 会作为第一个节点加入 Client 节点池，RpcClient 的请求会在池中负载均衡并在失败时自动切换节点。
+	// 不能和 RpcClient 同时设置，需要使用已有的客户端时通过 WithRPCClient 把它加入节点池
+	RpcOptions []ClientOption
+
+	pool   *Client // NewOption 根据 RpcOptions 创建的节点池
+	ownsWs bool    // WsClient 是否由 NewOption 创建
+}
+
+// WsMode ws客户端的连接方式
+type WsMode int
+
+const (
+	WsEager    WsMode = iota // 创建时立即连接，连接失败时返回错误
+	WsLazy                   // 第一次订阅时才连接，适用于可能不需要订阅的任务，连接不受 NewWallet 的 ctx 超时影响
+	WsDisabled               // 不创建ws客户端，Wallet.GetTransaction 通过HTTP轮询交易状态
+)
+
+// OptionError 配置项校验或者初始化失败
+type OptionError struct {
+	Field string // 出错的配置项
+	Err   error
+}
+
+func (e *OptionError) Error() string {
+	return fmt.Sprintf("无效的配置项 %s: %v", e.Field, e.Err)
+}
+
+func (e *OptionError) Unwrap() error {
+	return e.Err
 }
 
 // NewDefaultOption 构建一个新的配置项
+//
+// 配置无效或者ws连接失败时会 panic，在长期运行的服务中应该使用 NewOption
 func NewDefaultOption(ctx context.Context, option ...Option) Option {
+	result, err := NewOption(ctx, option...)
+	if err != nil {
+		panic(err)
+	}
+	return result
+}
+
+// NewOption 校验并构建一个新的配置项，配置无效或者ws连接失败时返回 *OptionError
+func NewOption(ctx context.Context, option ...Option) (Option, error) {
 	result := Option{
 		Headers: make(map[string]string),
 	}
 	if len(option) > 0 {
 		result = option[0]
 	}
-	if result.RpcUrl == "" {
+	// 传入的配置可能来自之前的 NewOption，其中的资源由调用方管理
+	result.pool, result.ownsWs = nil, false
+	result, err := result.withCluster()
+	if err != nil {
+		return result, err
+	}
+	if result.RpcUrl == "" && result.RpcClient == nil {
+		log.Warn("没有配置 RpcUrl，使用 DevNet 节点", "rpc", rpc.DevNet_RPC)
 		result.RpcUrl = rpc.DevNet_RPC
 	}
-	if result.WsUrl == "" {
+	if result.WsUrl == "" && result.WsClient == nil && result.WsMode != WsDisabled {
+		log.Warn("没有配置 WsUrl，使用 DevNet 节点", "ws", rpc.DevNet_WS)
 		result.WsUrl = rpc.DevNet_WS
 	}
 	if len(result.Headers) == 0 {
 		result.Headers = map[string]string{}
 	}
+	if err := result.Validate(); err != nil {
+		return result, err
+	}
 
 	if result.HTTPClient == nil {
 		if result.Proxy != "" {
 			client, err := NewProxyHttpClient(result.Proxy)
 			if err != nil {
-				panic(err)
+				return result, &OptionError{Field: "Proxy", Err: err}
 			}
 			result.HTTPClient = client
 		} else {
@@ -63,31 +147,205 @@ func NewDefaultOption(ctx context.Context, option ...Option) Option {
 	// 如果用户没有设置请求超时，则默认请求5秒后超时
 	result.HTTPClient.Timeout = result.TimeOut
 
-	if result.RpcClient == nil {
-		result.RpcClient = rpc.NewWithCustomRPCClient(jsonrpc.NewClientWithOpts(result.RpcUrl, &jsonrpc.RPCClientOpts{
+	ownsRpc := result.RpcClient == nil
+	if ownsRpc {
+		opts := &jsonrpc.RPCClientOpts{
 			HTTPClient:    result.HTTPClient,
 			CustomHeaders: result.Headers,
-		}))
+		}
+		if result.RateLimit > 0 {
+			result.RpcClient = rpc.NewWithCustomRPCClient(NewWithRateLimit(result.RpcUrl, result.RateLimit, opts))
+		} else {
+			result.RpcClient = rpc.NewWithCustomRPCClient(jsonrpc.NewClientWithOpts(result.RpcUrl, opts))
+		}
 		result.JsonRpcClient = jsonrpc.NewClientWithOpts(result.RpcUrl, &jsonrpc.RPCClientOpts{
 			HTTPClient: result.HTTPClient,
 		})
 	}
-	if result.WsClient == nil {
+	if result.WsClient == nil && result.WsMode != WsDisabled {
 		wsClient, err := ws.ConnectWithOptions(ctx, result.WsUrl, &ws.Options{
 			Proxy: result.WsProxy,
+			Lazy:  result.WsMode == WsLazy,
 		})
 		if err != nil {
-			panic(err)
+			return result, &OptionError{Field: "WsUrl", Err: err}
 		}
 		result.WsClient = wsClient
+		result.ownsWs = true
 	}
 
-	if result.Pkey == "" {
+	// 节点池会启动健康检查等后台任务，放在最后创建，之后不会再返回错误
+	if ownsRpc && len(result.RpcOptions) > 0 {
+		result.pool = NewRPCClient(ctx, rpc.Cluster{RPC: result.RpcUrl, WS: result.WsUrl}, append(
+			[]ClientOption{WithRPCClient(result.RpcClient, WithName(endpointHost(result.RpcUrl)))},
+			result.RpcOptions...,
+		)...)
+		result.RpcClient = result.pool.Client
+		// 配置已经用于创建节点池，返回的配置可以再次传给 NewWallet
+		result.RpcOptions = nil
+	}
+
+	if result.Pkey == "" && result.Signer == nil && result.Keystore == "" && result.Mnemonic == "" {
 		temp := solana.NewWallet()
 		result.Pkey = temp.PrivateKey.String()
 	}
 
-	return result
+	return result, nil
+}
+
+// release 关闭 NewOption 创建的节点池和ws客户端，用于 NewWallet 失败时释放资源
+func (o Option) release() {
+	if o.pool != nil {
+		o.pool.Close()
+	}
+	if o.ownsWs && o.WsClient != nil {
+		o.WsClient.Close()
+	}
+}
+
+// verifyGenesis 校验 RpcClient 的创世哈希，节点池会逐个校验其中的节点
+func (o Option) verifyGenesis(ctx context.Context) error {
+	if o.pool == nil {
+		return VerifyGenesisHash(ctx, o.RpcClient, o.GenesisHash)
+	}
+	for _, ep := range o.pool.Endpoints() {
+		if err := VerifyGenesisHash(ctx, ep.RPC(), o.GenesisHash); err != nil {
+			return fmt.Errorf("节点 %s: %w", ep.Name, err)
+		}
+	}
+	return nil
+}
+
+// Validate 校验配置项，不会发起任何网络请求
+func (o Option) Validate() error {
+	o, err := o.withCluster()
+	if err != nil {
+		return err
+	}
+	if o.RpcClient == nil {
+		if err := validateURL(o.RpcUrl, "http", "https"); err != nil {
+			return &OptionError{Field: "RpcUrl", Err: err}
+		}
+	} else if len(o.RpcOptions) > 0 {
+		return &OptionError{Field: "RpcOptions", Err: errors.New("设置了 RpcClient 时不会创建节点池，请使用 WithRPCClient 把 RpcClient 加入 RpcOptions")}
+	}
+	if o.WsMode < WsEager || o.WsMode > WsDisabled {
+		return &OptionError{Field: "WsMode", Err: fmt.Errorf("未知的ws连接方式 %d", o.WsMode)}
+	}
+	if o.WsClient == nil && o.WsMode != WsDisabled {
+		if err := validateURL(o.WsUrl, "ws", "wss"); err != nil {
+			return &OptionError{Field: "WsUrl", Err: err}
+		}
+	}
+	if o.Proxy != "" && o.HTTPClient == nil {
+		if err := validateURL(o.Proxy, "http", "https", "socks5"); err != nil {
+			return &OptionError{Field: "Proxy", Err: err}
+		}
+	}
+	if o.WsProxy != "" && o.WsClient == nil && o.WsMode != WsDisabled {
+		if err := validateURL(o.WsProxy, "http", "https", "socks5"); err != nil {
+			return &OptionError{Field: "WsProxy", Err: err}
+		}
+	}
+	if o.RateLimit < 0 {
+		return &OptionError{Field: "RateLimit", Err: fmt.Errorf("速率限制不能为负数: %d", o.RateLimit)}
+	}
+	if o.TimeOut < 0 {
+		return &OptionError{Field: "TimeOut", Err: fmt.Errorf("超时时间不能为负数: %s", o.TimeOut)}
+	}
+	if o.GenesisHash != "" {
+		if _, err := solana.HashFromBase58(o.GenesisHash); err != nil {
+			return &OptionError{Field: "GenesisHash", Err: fmt.Errorf("不是有效的base58哈希: %w", err)}
+		}
+	} else if o.VerifyGenesis {
+		return &OptionError{Field: "GenesisHash", Err: errors.New("开启了 VerifyGenesis 但是没有期望的创世哈希，请设置 GenesisHash 或者使用带创世哈希的 Cluster")}
+	}
+	keys := 0
+	for _, set := range []bool{o.Pkey != "", o.Signer != nil, o.Keystore != "", o.Mnemonic != ""} {
+		if set {
+			keys++
+		}
+	}
+	if keys > 1 {
+		return &OptionError{Field: "Signer", Err: errors.New("Pkey、Signer、Keystore 和 Mnemonic 只能设置一个")}
+	}
+	if o.Mnemonic != "" {
+		// 不要把助记词本身放进错误信息
+		if !hd.ValidateMnemonic(o.Mnemonic) {
+			return &OptionError{Field: "Mnemonic", Err: hd.ErrInvalidMnemonic}
+		}
+		if o.DerivationPath != "" {
+			if _, err := hd.ParsePath(o.DerivationPath); err != nil {
+				return &OptionError{Field: "DerivationPath", Err: err}
+			}
+		}
+	}
+	if o.Pkey != "" {
+		// 不要把私钥本身放进错误信息
+		if _, err := solana.PrivateKeyFromBase58(o.Pkey); err != nil {
+			return &OptionError{Field: "Pkey", Err: errors.New("不是有效的base58私钥")}
+		}
+	}
+	return nil
+}
+
+// String 打印配置时隐藏私钥
+func (o Option) String() string {
+	return fmt.Sprintf("Option{Cluster: %q, RpcUrl: %q, WsUrl: %q, WsMode: %d, Keystore: %q, Pkey: %s, Mnemonic: %s}",
+		o.Cluster, o.RpcUrl, o.WsUrl, o.WsMode, o.Keystore, redactSecret(o.Pkey), redactSecret(o.Mnemonic))
+}
+
+// GoString 防止 %#v 打印出私钥
+func (o Option) GoString() string {
+	return o.String()
+}
+
+// LogValue 实现 slog.LogValuer，日志中不记录私钥
+func (o Option) LogValue() slog.Value {
+	return slog.StringValue(o.String())
+}
+
+func redactSecret(secret string) string {
+	if secret == "" {
+		return `""`
+	}
+	return log.Redacted
+}
+
+// withCluster 使用 Cluster 填充没有设置的节点地址和创世哈希
+func (o Option) withCluster() (Option, error) {
+	if o.Cluster == "" {
+		return o, nil
+	}
+	cluster, ok := LookupCluster(o.Cluster)
+	if !ok {
+		return o, &OptionError{Field: "Cluster", Err: fmt.Errorf("未注册的集群 %q", o.Cluster)}
+	}
+	if o.RpcUrl == "" {
+		o.RpcUrl = cluster.RPC
+	}
+	if o.WsUrl == "" {
+		o.WsUrl = cluster.WS
+	}
+	if o.GenesisHash == "" {
+		o.GenesisHash = cluster.GenesisHash
+	}
+	return o, nil
+}
+
+// validateURL 校验地址可以被解析、包含主机并且使用指定的协议
+func validateURL(raw string, schemes ...string) error {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return fmt.Errorf("解析地址失败: %w", err)
+	}
+	if !slices.Contains(schemes, u.Scheme) {
+		return fmt.Errorf("不支持的协议 %q，需要 %s", u.Scheme, strings.Join(schemes, "/"))
+	}
+	if u.Host == "" {
+		return fmt.Errorf("地址 %q 缺少主机", raw)
+	}
+	return nil
 }
 
 // NewProxyHttpClient 创建一个支持代理的HTTP/HTTPS客户端
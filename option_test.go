@@ -0,0 +1,60 @@
+package gosolana
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/gagliardetto/solana-go/rpc"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_NewOptionValidation(t *testing.T) {
+	ctx := context.Background()
+	cases := map[string]Option{
+		"RpcUrl":  {RpcUrl: "127.0.0.1:8899"},
+		"WsUrl":   {WsUrl: "http://127.0.0.1:8900"},
+		"Proxy":   {Proxy: "://bad"},
+		"WsProxy": {WsProxy: "ftp://127.0.0.1"},
+		"TimeOut": {TimeOut: -1},
+		"Pkey":    {Pkey: "not-a-key"},
+		// 设置了 RpcClient 时 RpcOptions 不会生效
+		"RpcOptions": {RpcClient: rpc.New("http://127.0.0.1:8899"), RpcOptions: []ClientOption{WithBalancer(nil)}},
+	}
+	for field, opt := range cases {
+		_, err := NewOption(ctx, opt)
+		var optErr *OptionError
+		require.True(t, errors.As(err, &optErr), field)
+		require.Equal(t, field, optErr.Field)
+
+		_, err = NewWallet(ctx, opt)
+		require.ErrorAs(t, err, &optErr)
+		require.NotContains(t, err.Error(), "not-a-key", "错误信息中不应该包含私钥")
+	}
+
+	require.Panics(t, func() { NewDefaultOption(ctx, cases["Proxy"]) })
+}
+
+func Test_NewOptionReleasesPool(t *testing.T) {
+	ctx := context.Background()
+	node := newTestNode(t, 1)
+	check := WithHealthCheck(HealthCheck{Interval: 10 * time.Millisecond})
+
+	// ws连接失败时还没有创建节点池，不会启动健康检查
+	_, err := NewOption(ctx, Option{RpcUrl: node.URL, WsUrl: "ws://127.0.0.1:1", RpcOptions: []ClientOption{check}})
+	var optErr *OptionError
+	require.ErrorAs(t, err, &optErr)
+	require.Equal(t, "WsUrl", optErr.Field)
+	time.Sleep(50 * time.Millisecond)
+	require.Zero(t, node.hits.Load())
+
+	// NewWallet 失败时关闭 NewOption 创建的节点池
+	_, err = NewWallet(ctx, Option{RpcUrl: node.URL, WsMode: WsDisabled, Keystore: "missing.json", RpcOptions: []ClientOption{check}})
+	require.ErrorAs(t, err, &optErr)
+	require.Equal(t, "Keystore", optErr.Field)
+	time.Sleep(20 * time.Millisecond)
+	hits := node.hits.Load()
+	time.Sleep(50 * time.Millisecond)
+	require.Equal(t, hits, node.hits.Load())
+}
@@ -0,0 +1,189 @@
+package gosolana
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"math"
+	"slices"
+
+	"github.com/gagliardetto/solana-go"
+	"github.com/gagliardetto/solana-go/rpc"
+	"github.com/go-enols/gosolana/log"
+)
+
+// MaxPrioritizationFeeAccounts getRecentPrioritizationFees 单次请求最多支持的账户数量
+const MaxPrioritizationFeeAccounts = 128
+
+// FeeLevel 优先费的档位，即最近区块中优先费的百分位，零值表示没有设置，自定义的百分位使用 Percentile 创建，例如 Percentile(90)
+type FeeLevel struct {
+	// p 为百分位加一，让零值和第0个百分位区分开
+	p float64
+}
+
+var (
+	FeeMin    = Percentile(0)
+	FeeLow    = Percentile(25)
+	FeeMedium = Percentile(50)
+	FeeHigh   = Percentile(75)
+	FeeMax    = Percentile(100)
+)
+
+// Percentile 使用百分位创建档位，取值范围为0到100
+func Percentile(p float64) FeeLevel {
+	return FeeLevel{p: p + 1}
+}
+
+// IsZero 档位是否没有设置
+func (l FeeLevel) IsZero() bool {
+	return l.p == 0
+}
+
+// Percentile 返回档位对应的百分位
+func (l FeeLevel) Percentile() float64 {
+	return l.p - 1
+}
+
+func (l FeeLevel) String() string {
+	if l.IsZero() {
+		return "unset"
+	}
+	return fmt.Sprintf("p%v", l.Percentile())
+}
+
+// PriorityFeeEstimator 估算每个计算单元的优先费（单位为 micro-lamports）
+//
+// 默认使用 RecentFeeEstimator，也可以实现该接口接入 Helius 等服务商提供的接口，见 HeliusFeeEstimator
+type PriorityFeeEstimator interface {
+	EstimatePriorityFee(ctx context.Context, accounts []solana.PublicKey, level FeeLevel) (uint64, error)
+}
+
+// PriorityFeeOptions 估算优先费的配置
+type PriorityFeeOptions struct {
+	Level     FeeLevel             // 优先费的档位，没有设置时为 FeeMedium
+	Max       uint64               // 优先费的上限，为0时不限制
+	Estimator PriorityFeeEstimator // 默认使用钱包的客户端创建 RecentFeeEstimator
+}
+
+// RecentFeeEstimator 通过 getRecentPrioritizationFees 获取最近150个区块中写入这些账户的交易的最低优先费，再计算百分位
+//
+// 账户超过 MaxPrioritizationFeeAccounts 时分批请求，每个区块取各批中最高的优先费
+type RecentFeeEstimator struct {
+	Client *rpc.Client
+}
+
+func (e *RecentFeeEstimator) EstimatePriorityFee(ctx context.Context, accounts []solana.PublicKey, level FeeLevel) (uint64, error) {
+	slots := map[uint64]uint64{}
+	for chunk := range slices.Chunk(accounts, MaxPrioritizationFeeAccounts) {
+		out, err := e.Client.GetRecentPrioritizationFees(ctx, chunk)
+		if err != nil {
+			return 0, err
+		}
+		for _, fee := range out {
+			slots[fee.Slot] = max(slots[fee.Slot], fee.PrioritizationFee)
+		}
+	}
+	return percentile(slices.Collect(maps.Values(slots)), level.Percentile()), nil
+}
+
+// heliusLevels Helius 支持的档位和对应的百分位
+var heliusLevels = []struct {
+	name  string
+	level float64
+}{
+	{"Min", 0},
+	{"Low", 25},
+	{"Medium", 50},
+	{"High", 75},
+	{"VeryHigh", 95},
+	{"UnsafeMax", 100},
+}
+
+// HeliusFeeEstimator 使用 Helius 的 getPriorityFeeEstimate 接口，自定义的百分位会使用不低于它的最近档位
+//
+// 账户超过 MaxPrioritizationFeeAccounts 时分批请求，返回各批中最高的优先费
+type HeliusFeeEstimator struct {
+	Client *rpc.Client
+}
+
+func (e *HeliusFeeEstimator) EstimatePriorityFee(ctx context.Context, accounts []solana.PublicKey, level FeeLevel) (uint64, error) {
+	name := heliusLevels[len(heliusLevels)-1].name
+	for _, l := range heliusLevels {
+		if l.level >= level.Percentile() {
+			name = l.name
+			break
+		}
+	}
+	var fee float64
+	for chunk := range slices.Chunk(accounts, MaxPrioritizationFeeAccounts) {
+		var out struct {
+			PriorityFeeEstimate float64 `json:"priorityFeeEstimate"`
+		}
+		err := e.Client.RPCCallForInto(ctx, &out, "getPriorityFeeEstimate", []any{map[string]any{
+			"accountKeys": chunk,
+			"options":     map[string]any{"priorityLevel": name},
+		}})
+		if err != nil {
+			return 0, err
+		}
+		fee = max(fee, out.PriorityFeeEstimate)
+	}
+	return uint64(math.Ceil(fee)), nil
+}
+
+// EstimatePriorityFee 估算写入这些指令的可写账户需要的优先费（单位为 micro-lamports），可以用于 TxBuilder.SetComputeUnitPrice
+func (w *Wallet) EstimatePriorityFee(ctx context.Context, instruction []solana.Instruction, opts ...PriorityFeeOptions) (uint64, error) {
+	opt := PriorityFeeOptions{}
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+	if opt.Level.IsZero() {
+		opt.Level = FeeMedium
+	}
+	if p := opt.Level.Percentile(); p < 0 || p > 100 {
+		return 0, fmt.Errorf("优先费的百分位 %v 必须在0到100之间", opt.Level.Percentile())
+	}
+	if opt.Estimator == nil {
+		opt.Estimator = &RecentFeeEstimator{Client: w.GetClient()}
+	}
+
+	accounts := WritableAccounts(instruction)
+	if len(accounts) == 0 {
+		return 0, errors.New("指令中没有可写的账户")
+	}
+	fee, err := opt.Estimator.EstimatePriorityFee(ctx, accounts, opt.Level)
+	if err != nil {
+		log.Error("估算优先费失败", "error", err)
+		return 0, err
+	}
+	if opt.Max > 0 && fee > opt.Max {
+		log.Debug("优先费超过上限", "fee", fee, "max", opt.Max)
+		fee = opt.Max
+	}
+	return fee, nil
+}
+
+// WritableAccounts 返回指令中所有可写的账户，去掉重复的账户，优先费只和写锁竞争的账户有关
+func WritableAccounts(instruction []solana.Instruction) []solana.PublicKey {
+	var accounts []solana.PublicKey
+	for _, ins := range instruction {
+		for _, meta := range ins.Accounts() {
+			if meta.IsWritable && !slices.Contains(accounts, meta.PublicKey) {
+				accounts = append(accounts, meta.PublicKey)
+			}
+		}
+	}
+	return accounts
+}
+
+// percentile 使用最近排名法计算百分位，没有数据时返回0
+func percentile(values []uint64, p float64) uint64 {
+	if len(values) == 0 {
+		return 0
+	}
+	sorted := slices.Clone(values)
+	slices.Sort(sorted)
+	rank := int(math.Ceil(p / 100 * float64(len(sorted))))
+	return sorted[min(max(rank-1, 0), len(sorted)-1)]
+}
@@ -0,0 +1,141 @@
+package gosolana
+
+import (
+	"context"
+	"net/http"
+	"sync/atomic"
+	"testing"
+
+	"github.com/gagliardetto/solana-go"
+	computebudget "github.com/gagliardetto/solana-go/programs/compute-budget"
+	"github.com/gagliardetto/solana-go/programs/system"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Percentile(t *testing.T) {
+	fees := []uint64{0, 0, 100, 400, 300, 200, 0, 1000, 500, 600}
+	require.Equal(t, uint64(0), percentile(nil, 50))
+	require.Equal(t, uint64(0), percentile(fees, FeeMin.Percentile()))
+	require.Equal(t, uint64(0), percentile(fees, FeeLow.Percentile()))
+	require.Equal(t, uint64(200), percentile(fees, FeeMedium.Percentile()))
+	require.Equal(t, uint64(500), percentile(fees, FeeHigh.Percentile()))
+	require.Equal(t, uint64(1000), percentile(fees, FeeMax.Percentile()))
+	require.Equal(t, uint64(0), fees[0], "不应该修改原来的数据")
+
+	require.True(t, FeeLevel{}.IsZero())
+	require.False(t, FeeMin.IsZero(), "最低档位不应该等于零值")
+}
+
+func Test_EstimatePriorityFee(t *testing.T) {
+	ctx := context.Background()
+	node, sent := newTxNode(t)
+	var requested []any
+	next := node.handler
+	node.handler = func(w http.ResponseWriter, req map[string]any) bool {
+		switch req["method"] {
+		case "getRecentPrioritizationFees":
+			requested = req["params"].([]any)[0].([]any)
+			fees := make([]map[string]any, 0, 10)
+			for i := range 10 {
+				fees = append(fees, map[string]any{"slot": i, "prioritizationFee": i * 100})
+			}
+			writeResult(w, req["id"], fees)
+		case "getPriorityFeeEstimate":
+			options := req["params"].([]any)[0].(map[string]any)["options"].(map[string]any)
+			writeResult(w, req["id"], map[string]any{"priorityFeeEstimate": map[string]float64{
+				"Low": 10, "Medium": 20, "High": 30, "VeryHigh": 40.5,
+			}[options["priorityLevel"].(string)]})
+		default:
+			return next(w, req)
+		}
+		return true
+	}
+	wallet, err := NewWallet(ctx, Option{RpcUrl: node.URL, WsMode: WsDisabled})
+	require.NoError(t, err)
+	recipient := solana.NewWallet().PublicKey()
+	transfer := []solana.Instruction{system.NewTransferInstruction(1, wallet.PublicKey(), recipient).Build()}
+
+	fee, err := wallet.EstimatePriorityFee(ctx, transfer)
+	require.NoError(t, err)
+	require.Equal(t, uint64(400), fee)
+	require.ElementsMatch(t, []any{wallet.PublicKey().String(), recipient.String()}, requested)
+	fee, err = wallet.EstimatePriorityFee(ctx, transfer, PriorityFeeOptions{Level: FeeMin})
+	require.NoError(t, err)
+	require.Equal(t, uint64(0), fee)
+
+	fee, err = wallet.EstimatePriorityFee(ctx, transfer, PriorityFeeOptions{Level: Percentile(90)})
+	require.NoError(t, err)
+	require.Equal(t, uint64(800), fee)
+	fee, err = wallet.EstimatePriorityFee(ctx, transfer, PriorityFeeOptions{Level: FeeHigh, Max: 300})
+	require.NoError(t, err)
+	require.Equal(t, uint64(300), fee)
+	_, err = wallet.EstimatePriorityFee(ctx, transfer, PriorityFeeOptions{Level: Percentile(101)})
+	require.Error(t, err)
+
+	helius := &HeliusFeeEstimator{Client: wallet.GetClient()}
+	fee, err = wallet.EstimatePriorityFee(ctx, transfer, PriorityFeeOptions{Level: FeeHigh, Estimator: helius})
+	require.NoError(t, err)
+	require.Equal(t, uint64(30), fee)
+	fee, err = wallet.EstimatePriorityFee(ctx, transfer, PriorityFeeOptions{Level: Percentile(90), Estimator: helius})
+	require.NoError(t, err)
+	require.Equal(t, uint64(41), fee)
+
+	// 发送交易时自动设置计算单元的价格
+	ok, err := wallet.SendTransactionWithOpts(ctx, transfer, SendOptions{PriorityFee: &PriorityFeeOptions{Level: FeeLow}})
+	require.NoError(t, err)
+	require.True(t, ok)
+	tx := sent.Load()
+	require.NoError(t, tx.VerifySignatures())
+	decoded, err := computebudget.DecodeInstruction(nil, tx.Message.Instructions[0].Data)
+	require.NoError(t, err)
+	require.Equal(t, uint64(200), decoded.Impl.(*computebudget.SetComputeUnitPrice).MicroLamports)
+
+	_, err = wallet.NewTxBuilder().
+		AddInstruction(computebudget.NewSetComputeUnitPriceInstruction(1).Build()).
+		AddInstruction(transfer...).
+		EstimatePriorityFee().
+		Build(ctx)
+	require.ErrorIs(t, err, ErrDuplicateComputeBudget)
+}
+
+func Test_EstimatePriorityFeeChunked(t *testing.T) {
+	ctx := context.Background()
+	node := newTestNode(t, 1)
+	var requests atomic.Int64
+	node.handler = func(w http.ResponseWriter, req map[string]any) bool {
+		if req["method"] != "getRecentPrioritizationFees" {
+			return false
+		}
+		requests.Add(1)
+		keys := req["params"].([]any)[0].([]any)
+		if !assert.LessOrEqual(t, len(keys), MaxPrioritizationFeeAccounts) {
+			http.Error(w, "too many accounts", http.StatusBadRequest)
+			return true
+		}
+		// 两批账户在同一个区块中的优先费不同，合并后每个区块取最高的优先费
+		fees := make([]map[string]any, 0, 10)
+		for i := range 10 {
+			fee := i * 100
+			if len(keys) < MaxPrioritizationFeeAccounts {
+				fee = (10 - i) * 100
+			}
+			fees = append(fees, map[string]any{"slot": i, "prioritizationFee": fee})
+		}
+		writeResult(w, req["id"], fees)
+		return true
+	}
+	wallet, err := NewWallet(ctx, Option{RpcUrl: node.URL, WsMode: WsDisabled})
+	require.NoError(t, err)
+
+	var transfers []solana.Instruction
+	for range MaxPrioritizationFeeAccounts + 2 {
+		transfers = append(transfers, system.NewTransferInstruction(1, wallet.PublicKey(), solana.NewWallet().PublicKey()).Build())
+	}
+	require.Len(t, WritableAccounts(transfers), MaxPrioritizationFeeAccounts+3)
+
+	fee, err := wallet.EstimatePriorityFee(ctx, transfers)
+	require.NoError(t, err)
+	require.Equal(t, int64(2), requests.Load())
+	require.Equal(t, uint64(700), fee)
+}
@@ -0,0 +1,105 @@
+package gosolana
+
+import (
+	"context"
+	"errors"
+	"io"
+	"math/rand/v2"
+	"net"
+	"net/http"
+	"slices"
+	"syscall"
+	"time"
+
+	"github.com/gagliardetto/solana-go/rpc/jsonrpc"
+)
+
+// RetryPolicy 请求失败后在其他节点上重试的策略
+type RetryPolicy struct {
+	MaxAttempts int           // 最大尝试次数(包含第一次请求)，小于等于1时不进行重试
+	BaseDelay   time.Duration // 第一次重试前的等待时间，之后每次翻倍
+	MaxDelay    time.Duration // 单次等待时间的上限
+
+	// 可以重试的 JSON-RPC 错误码
+	//
+	// HTTP 5xx、429、超时以及连接被重置总是可以重试的
+	RetryableCodes []int
+}
+
+// DefaultRetryPolicy 默认的重试策略，最多尝试3次
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{
+		MaxAttempts: 3,
+		BaseDelay:   100 * time.Millisecond,
+		MaxDelay:    2 * time.Second,
+		RetryableCodes: []int{
+			-32004, // Block not available for slot
+			-32005, // Node is unhealthy / behind
+			-32014, // Block status not yet available
+			-32016, // Minimum context slot has not been reached
+			-32429, // 部分服务商使用的限流错误码
+		},
+	}
+}
+
+// WithRetryPolicy 设置失败重试策略，未设置时使用 DefaultRetryPolicy
+func WithRetryPolicy(policy RetryPolicy) ClientOption {
+	return func(ctx context.Context, client *Client) {
+		client.retry = policy
+	}
+}
+
+// Retryable 判断一个错误是否可以换一个节点重试
+func (p RetryPolicy) Retryable(err error) bool {
+	if err == nil {
+		return false
+	}
+
+	var httpErr *jsonrpc.HTTPError
+	if errors.As(err, &httpErr) {
+		return httpErr.Code == http.StatusTooManyRequests || httpErr.Code >= http.StatusInternalServerError
+	}
+
+	var rpcErr *jsonrpc.RPCError
+	if errors.As(err, &rpcErr) {
+		return slices.Contains(p.RetryableCodes, rpcErr.Code)
+	}
+
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return true
+	}
+	return errors.Is(err, syscall.ECONNRESET) ||
+		errors.Is(err, syscall.ECONNREFUSED) ||
+		errors.Is(err, io.EOF) ||
+		errors.Is(err, io.ErrUnexpectedEOF)
+}
+
+// backoff 第 attempt 次重试前需要等待的时间，使用指数退避加上随机抖动
+func (p RetryPolicy) backoff(attempt int) time.Duration {
+	if p.BaseDelay <= 0 {
+		return 0
+	}
+	delay := p.BaseDelay << (attempt - 1)
+	if delay <= 0 || (p.MaxDelay > 0 && delay > p.MaxDelay) {
+		delay = p.MaxDelay
+	}
+	// 在 [delay/2, delay] 之间随机，避免所有调用方同时重试
+	half := delay / 2
+	return half + rand.N(delay-half+1)
+}
+
+// sleepContext 等待一段时间，上下文结束时提前返回
+func sleepContext(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return ctx.Err()
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
@@ -0,0 +1,128 @@
+package gosolana
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	"github.com/gagliardetto/solana-go/rpc/jsonrpc"
+)
+
+// 内置的方法分组，可以直接作为 WithRoute 的 key 使用
+const (
+	MethodGroupSend  = "send"  // 发送交易
+	MethodGroupHeavy = "heavy" // 需要归档节点或者开销较大的查询
+)
+
+// defaultMethodGroups 内置方法分组包含的方法
+func defaultMethodGroups() map[string][]string {
+	return map[string][]string{
+		MethodGroupSend: {
+			"sendTransaction",
+		},
+		MethodGroupHeavy: {
+			"getProgramAccounts",
+			"getSignaturesForAddress",
+			"getConfirmedSignaturesForAddress2",
+			"getTokenAccountsByOwner",
+			"getTokenAccountsByDelegate",
+			"getTokenLargestAccounts",
+			"getLargestAccounts",
+			"getBlock",
+			"getBlocks",
+			"getBlocksWithLimit",
+			"getTransaction",
+		},
+	}
+}
+
+// WithGroup 将节点加入一个或多个分组，分组用于按方法路由
+func WithGroup(groups ...string) EndpointOption {
+	return func(ep *Endpoint) {
+		ep.Groups = append(ep.Groups, groups...)
+	}
+}
+
+// WithMethodGroup 定义或者覆盖一个方法分组
+func WithMethodGroup(name string, methods ...string) ClientOption {
+	return func(ctx context.Context, client *Client) {
+		client.methodGroups[name] = methods
+	}
+}
+
+// WithRoute 将方法或者方法分组的请求路由到指定的节点分组
+//
+// key 为 JSON-RPC 方法名，例如 sendTransaction，或者方法分组名，例如 MethodGroupSend。
+// 方法名的规则优先于方法分组的规则，方法属于多个方法分组时使用最先添加的规则
+func WithRoute(key, group string) ClientOption {
+	return func(ctx context.Context, client *Client) {
+		if _, ok := client.routes[key]; !ok {
+			client.routeOrder = append(client.routeOrder, key)
+		}
+		client.routes[key] = group
+	}
+}
+
+// WithDefaultRoute 没有匹配任何路由规则的请求发送到指定的节点分组，未设置时使用池中的所有节点
+func WithDefaultRoute(group string) ClientOption {
+	return func(ctx context.Context, client *Client) {
+		client.defaultRoute = group
+	}
+}
+
+// InGroup 节点是否属于指定分组
+func (e *Endpoint) InGroup(group string) bool {
+	return slices.Contains(e.Groups, group)
+}
+
+// routeGroup 返回方法对应的节点分组，空字符串表示所有节点
+func (c *Client) routeGroup(method string) string {
+	if group, ok := c.routes[method]; ok {
+		return group
+	}
+	for _, key := range c.routeOrder {
+		if slices.Contains(c.methodGroups[key], method) {
+			return c.routes[key]
+		}
+	}
+	return c.defaultRoute
+}
+
+// route 返回方法可以使用的节点
+func (c *Client) route(method string) ([]*Endpoint, error) {
+	group := c.routeGroup(method)
+	if group == "" {
+		return c.endpoints, nil
+	}
+	endpoints := c.groups[group]
+	if len(endpoints) == 0 {
+		return nil, fmt.Errorf("%w: 方法 %s 路由到的分组 %s 中没有节点", ErrNoEndpoint, method, group)
+	}
+	return endpoints, nil
+}
+
+// routeBatch 批量请求中所有方法路由到同一个分组时使用该分组，否则使用默认路由
+func (c *Client) routeBatch(requests jsonrpc.RPCRequests) ([]*Endpoint, error) {
+	if len(requests) == 0 {
+		return c.endpoints, nil
+	}
+	group := c.routeGroup(requests[0].Method)
+	for _, req := range requests[1:] {
+		if c.routeGroup(req.Method) != group {
+			return c.route("")
+		}
+	}
+	return c.route(requests[0].Method)
+}
+
+// buildGroups 按照分组整理节点
+func (c *Client) buildGroups() {
+	c.groups = map[string][]*Endpoint{}
+	for _, ep := range c.endpoints {
+		for _, group := range ep.Groups {
+			if !slices.Contains(c.groups[group], ep) {
+				c.groups[group] = append(c.groups[group], ep)
+			}
+		}
+	}
+}
@@ -3,38 +3,67 @@ package gosolana
 import (
 	"context"
 	"io"
+	"maps"
 	"net/http"
+	"net/url"
+	"strconv"
+	"sync"
+	"time"
 
 	"github.com/gagliardetto/solana-go/rpc"
 	"github.com/gagliardetto/solana-go/rpc/jsonrpc"
-	"go.uber.org/ratelimit"
+	"github.com/go-enols/gosolana/log"
+	"golang.org/x/time/rate"
 )
 
 var _ rpc.JSONRPCClient = &clientWithRateLimiting{}
 
 type clientWithRateLimiting struct {
 	rpcClient   jsonrpc.RPCClient
-	rateLimiter ratelimit.Limiter
+	rateLimiter *CreditLimiter
 }
 
 // NewWithRateLimit creates a new rate-limitted Solana RPC client.
+//
+// 每个请求消耗1个积分，指向同一个节点的客户端共享同一个限流器
 func NewWithRateLimit(
 	rpcEndpoint string,
 	rps int, // requests per second
 	opts *jsonrpc.RPCClientOpts,
 ) rpc.JSONRPCClient {
+	return NewWithCreditLimit(rpcEndpoint, SharedCreditLimiter(rpcEndpoint, rps, nil), opts)
+}
+
+// NewWithCreditLimit 创建一个按照方法积分限流的 Solana RPC 客户端
+//
+// 节点返回 HTTP 429 或者 Retry-After 时限流器会自动降低速率，见 CreditLimiter
+func NewWithCreditLimit(
+	rpcEndpoint string,
+	limiter *CreditLimiter,
+	opts *jsonrpc.RPCClientOpts,
+) rpc.JSONRPCClient {
+	options := jsonrpc.RPCClientOpts{}
+	if opts != nil {
+		options = *opts
+	}
+	if options.HTTPClient == nil {
+		options.HTTPClient = &http.Client{}
+	}
+	options.HTTPClient = &throttleObserver{next: options.HTTPClient, limiter: limiter}
 
-	rpcClient := jsonrpc.NewClientWithOpts(rpcEndpoint, opts)
+	rpcClient := jsonrpc.NewClientWithOpts(rpcEndpoint, &options)
 
 	return &clientWithRateLimiting{
 		rpcClient:   rpcClient,
-		rateLimiter: ratelimit.New(rps),
+		rateLimiter: limiter,
 	}
 }
 
 func (wr *clientWithRateLimiting) CallForInto(ctx context.Context, out interface{}, method string, params []interface{}) error {
-	wr.rateLimiter.Take()
-	return wr.rpcClient.CallForInto(ctx, &out, method, params)
+	if err := wr.rateLimiter.Wait(ctx, wr.rateLimiter.Cost(method)); err != nil {
+		return err
+	}
+	return wr.rpcClient.CallForInto(ctx, out, method, params)
 }
 
 func (wr *clientWithRateLimiting) CallWithCallback(
@@ -43,15 +72,24 @@ func (wr *clientWithRateLimiting) CallWithCallback(
 	params []interface{},
 	callback func(*http.Request, *http.Response) error,
 ) error {
-	wr.rateLimiter.Take()
+	if err := wr.rateLimiter.Wait(ctx, wr.rateLimiter.Cost(method)); err != nil {
+		return err
+	}
 	return wr.rpcClient.CallWithCallback(ctx, method, params, callback)
 }
 
+// CallBatch 批量请求消耗所有请求积分的总和
 func (wr *clientWithRateLimiting) CallBatch(
 	ctx context.Context,
 	requests jsonrpc.RPCRequests,
 ) (jsonrpc.RPCResponses, error) {
-	wr.rateLimiter.Take()
+	credits := 0
+	for _, req := range requests {
+		credits += wr.rateLimiter.Cost(req.Method)
+	}
+	if err := wr.rateLimiter.Wait(ctx, credits); err != nil {
+		return nil, err
+	}
 	return wr.rpcClient.CallBatch(ctx, requests)
 }
 
@@ -62,3 +100,227 @@ func (cl *clientWithRateLimiting) Close() error {
 	}
 	return nil
 }
+
+// CreditLimiter 基于积分的限流器
+//
+// 像 Helius 等服务商一样，每个方法可以消耗不同数量的积分，未配置的方法消耗1个积分。
+//
+// 限流器使用 AIMD 的方式自适应节点的限流：收到 HTTP 429 时速率减半，并在 Retry-After 指定的时间内暂停请求，
+// 之后每秒钟增加初始速率的5%，直到恢复到初始速率
+type CreditLimiter struct {
+	limiter *rate.Limiter
+	costs   map[string]int
+	maxCost int
+
+	lock        sync.Mutex
+	maxRate     float64   // 初始速率，也是速率的上限
+	minRate     float64   // 速率的下限
+	adjustedAt  time.Time // 上一次调整速率的时间
+	pausedUntil time.Time // Retry-After 要求暂停到的时间
+}
+
+const (
+	limiterAdjustInterval = time.Second // 两次调整速率之间的最小间隔
+	limiterDecrease       = 0.5         // 每次限流时速率乘以的系数
+	limiterIncrease       = 0.05        // 每次增加初始速率的比例
+)
+
+// NewCreditLimiter 创建一个积分限流器
+//
+//	creditsPerSecond 每秒可以消耗的积分
+//	costs 每个方法消耗的积分（可选）
+func NewCreditLimiter(creditsPerSecond int, costs map[string]int) *CreditLimiter {
+	creditsPerSecond = max(creditsPerSecond, 1)
+	copied := make(map[string]int, len(costs))
+	maxCost := 1
+	for method, cost := range costs {
+		copied[method] = cost
+		maxCost = max(maxCost, cost)
+	}
+	return &CreditLimiter{
+		limiter: rate.NewLimiter(rate.Limit(creditsPerSecond), max(creditsPerSecond, maxCost)),
+		costs:   copied,
+		maxCost: maxCost,
+		maxRate: float64(creditsPerSecond),
+		minRate: max(float64(creditsPerSecond)/20, 1),
+	}
+}
+
+// Cost 返回方法消耗的积分
+func (l *CreditLimiter) Cost(method string) int {
+	if cost, ok := l.costs[method]; ok {
+		return cost
+	}
+	return 1
+}
+
+// Rate 返回当前实际生效的速率，单位为每秒积分
+func (l *CreditLimiter) Rate() float64 {
+	return float64(l.limiter.Limit())
+}
+
+// Wait 等待直到可以消耗指定数量的积分
+func (l *CreditLimiter) Wait(ctx context.Context, credits int) error {
+	l.lock.Lock()
+	paused := time.Until(l.pausedUntil)
+	l.lock.Unlock()
+	if paused > 0 {
+		if err := sleepContext(ctx, paused); err != nil {
+			return err
+		}
+	}
+
+	for credits > 0 {
+		n := min(credits, l.limiter.Burst())
+		if err := l.limiter.WaitN(ctx, n); err != nil {
+			return err
+		}
+		credits -= n
+	}
+	return nil
+}
+
+// Throttled 节点返回了限流响应，降低速率并在 retryAfter 内暂停请求
+func (l *CreditLimiter) Throttled(retryAfter time.Duration) {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	now := time.Now()
+	if retryAfter > 0 && now.Add(retryAfter).After(l.pausedUntil) {
+		l.pausedUntil = now.Add(retryAfter)
+	}
+	// 同一批并发请求收到的多个429只降低一次速率
+	if now.Sub(l.adjustedAt) < limiterAdjustInterval {
+		return
+	}
+	l.setRate(max(l.Rate()*limiterDecrease, l.minRate), now)
+}
+
+// Succeeded 请求成功，距离上一次调整超过1秒时增加速率
+func (l *CreditLimiter) Succeeded() {
+	if l.Rate() >= l.maxRate {
+		return
+	}
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	now := time.Now()
+	if now.Sub(l.adjustedAt) < limiterAdjustInterval {
+		return
+	}
+	l.setRate(min(l.Rate()+max(l.maxRate*limiterIncrease, 1), l.maxRate), now)
+}
+
+func (l *CreditLimiter) setRate(r float64, now time.Time) {
+	l.adjustedAt = now
+	l.limiter.SetLimitAt(now, rate.Limit(r))
+	l.limiter.SetBurstAt(now, max(int(r), l.maxCost))
+}
+
+// throttleObserver 观察HTTP响应，将限流信息反馈给限流器
+type throttleObserver struct {
+	next    jsonrpc.HTTPClient
+	limiter *CreditLimiter
+}
+
+func (o *throttleObserver) Do(req *http.Request) (*http.Response, error) {
+	resp, err := o.next.Do(req)
+	if err != nil {
+		return resp, err
+	}
+	switch retryAfter := resp.Header.Get("Retry-After"); {
+	case resp.StatusCode == http.StatusTooManyRequests || retryAfter != "":
+		o.limiter.Throttled(parseRetryAfter(retryAfter))
+	case resp.StatusCode < http.StatusBadRequest:
+		o.limiter.Succeeded()
+	}
+	return resp, nil
+}
+
+func (o *throttleObserver) CloseIdleConnections() {
+	o.next.CloseIdleConnections()
+}
+
+// parseRetryAfter 解析 Retry-After 头，支持秒数和HTTP日期两种格式
+func parseRetryAfter(value string) time.Duration {
+	if value == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(value); err == nil {
+		return time.Duration(max(seconds, 0)) * time.Second
+	}
+	if at, err := http.ParseTime(value); err == nil {
+		return max(time.Until(at), 0)
+	}
+	return 0
+}
+
+var (
+	sharedLimiterLock sync.Mutex
+	sharedLimiters    = map[string]*CreditLimiter{}
+)
+
+// SharedCreditLimiter 返回节点共享的积分限流器
+//
+// 同一个节点第一次调用时按照参数创建限流器，之后的调用返回已有的限流器，
+// 参数与已有的限流器不一致时记录警告日志，例如 Option.RateLimit 和配置文件中节点的 rate_limit 不同
+func SharedCreditLimiter(rpcEndpoint string, creditsPerSecond int, costs map[string]int) *CreditLimiter {
+	key := limiterKey(rpcEndpoint)
+
+	sharedLimiterLock.Lock()
+	defer sharedLimiterLock.Unlock()
+	if limiter, ok := sharedLimiters[key]; ok {
+		if limiter.maxRate != float64(max(creditsPerSecond, 1)) || !maps.Equal(limiter.costs, costs) {
+			log.Warn("节点已经有共享的限流器，忽略新的限流参数",
+				"endpoint", endpointHost(rpcEndpoint),
+				"creditsPerSecond", limiter.maxRate,
+				"ignoredCreditsPerSecond", creditsPerSecond,
+			)
+		}
+		return limiter
+	}
+	limiter := NewCreditLimiter(creditsPerSecond, costs)
+	sharedLimiters[key] = limiter
+	return limiter
+}
+
+// LookupCreditLimiter 返回节点已有的共享限流器，不会创建新的限流器，
+// 可以用于查看 NewWithRateLimit 或者 Option.RateLimit 创建的限流器当前的速率
+func LookupCreditLimiter(rpcEndpoint string) (*CreditLimiter, bool) {
+	sharedLimiterLock.Lock()
+	defer sharedLimiterLock.Unlock()
+	limiter, ok := sharedLimiters[limiterKey(rpcEndpoint)]
+	return limiter, ok
+}
+
+// ReleaseSharedCreditLimiter 移除节点共享的限流器，之后的 SharedCreditLimiter 会按照新的参数创建限流器，
+// 已经使用旧限流器的客户端不受影响
+func ReleaseSharedCreditLimiter(rpcEndpoint string) {
+	sharedLimiterLock.Lock()
+	defer sharedLimiterLock.Unlock()
+	delete(sharedLimiters, limiterKey(rpcEndpoint))
+}
+
+// endpointHost 日志和节点名称中只使用节点的主机名，查询参数中可能有 api-key
+func endpointHost(rpcEndpoint string) string {
+	if u, err := url.Parse(rpcEndpoint); err == nil && u.Host != "" {
+		return u.Host
+	}
+	return ""
+}
+
+// limiterKey 忽略协议和末尾斜杠，保留查询参数，因为很多服务商在查询参数中携带 api-key
+func limiterKey(rpcEndpoint string) string {
+	u, err := url.Parse(rpcEndpoint)
+	if err != nil || u.Host == "" {
+		return rpcEndpoint
+	}
+	key := u.Host + u.EscapedPath()
+	for len(key) > 0 && key[len(key)-1] == '/' {
+		key = key[:len(key)-1]
+	}
+	if u.RawQuery != "" {
+		key += "?" + u.RawQuery
+	}
+	return key
+}
@@ -0,0 +1,99 @@
+package gosolana
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gagliardetto/solana-go"
+	"github.com/gagliardetto/solana-go/rpc"
+	"github.com/gagliardetto/solana-go/rpc/jsonrpc"
+	"github.com/go-enols/gosolana/log"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_RateLimitedClientDecodes(t *testing.T) {
+	ctx := context.Background()
+	node := newTestNode(t, 7)
+	client := rpc.NewWithCustomRPCClient(NewWithRateLimit(node.URL, 100, nil))
+
+	out, err := client.GetBalance(ctx, solana.SystemProgramID, rpc.CommitmentProcessed)
+	require.NoError(t, err)
+	require.Equal(t, uint64(7), out.Value)
+}
+
+func Test_CreditLimiter(t *testing.T) {
+	ctx := context.Background()
+	node := newTestNode(t, 1)
+
+	costs := map[string]int{"getProgramAccounts": 10}
+	limiter := SharedCreditLimiter(node.URL+"/", 10, costs)
+	var buf bytes.Buffer
+	defer log.SetLogger(log.Logger())
+	log.SetLogger(slog.New(slog.NewTextHandler(&buf, nil)))
+	require.Same(t, limiter, SharedCreditLimiter(node.URL, 10, costs))
+	require.Empty(t, buf.String())
+	require.Same(t, limiter, SharedCreditLimiter(node.URL, 1, nil), "同一个节点应该共享限流器")
+	require.Contains(t, buf.String(), "ignoredCreditsPerSecond=1", "参数不一致时应该记录警告")
+	require.Equal(t, 10, limiter.Cost("getProgramAccounts"))
+	require.Equal(t, 1, limiter.Cost("getBalance"))
+
+	client := NewWithCreditLimit(node.URL, limiter, nil)
+	// 批量请求消耗所有请求积分的总和，即11个积分
+	_, err := client.CallBatch(ctx, jsonrpc.RPCRequests{
+		jsonrpc.NewRequest("getBalance"),
+		jsonrpc.NewRequest("getProgramAccounts"),
+	})
+	require.NoError(t, err)
+
+	start := time.Now()
+	var out any
+	require.NoError(t, client.CallForInto(ctx, &out, "getProgramAccounts", nil))
+	require.GreaterOrEqual(t, time.Since(start), 900*time.Millisecond, "批量请求消耗了11个积分，需要等待大约1秒")
+
+	// 查询已有的限流器不会记录冲突的警告
+	buf.Reset()
+	found, ok := LookupCreditLimiter(node.URL + "/")
+	require.True(t, ok)
+	require.Same(t, limiter, found)
+	require.Equal(t, 10.0, found.Rate())
+	require.Empty(t, buf.String())
+
+	ReleaseSharedCreditLimiter(node.URL)
+	_, ok = LookupCreditLimiter(node.URL)
+	require.False(t, ok, "查询不应该创建限流器")
+	require.NotSame(t, limiter, SharedCreditLimiter(node.URL, 1, nil))
+	ReleaseSharedCreditLimiter(node.URL)
+}
+
+func Test_CreditLimiterAdaptive(t *testing.T) {
+	ctx := context.Background()
+	node := newTestNode(t, 1)
+	var throttled atomic.Bool
+	throttled.Store(true)
+	node.handler = func(w http.ResponseWriter, req map[string]any) bool {
+		if throttled.Load() {
+			w.Header().Set("Retry-After", "1")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return true
+		}
+		return false
+	}
+
+	limiter := NewCreditLimiter(100, nil)
+	client := NewWithCreditLimit(node.URL, limiter, nil)
+	var out any
+	require.Error(t, client.CallForInto(ctx, &out, "getBalance", nil))
+	require.Equal(t, 50.0, limiter.Rate())
+
+	// Retry-After 期间暂停请求
+	throttled.Store(false)
+	start := time.Now()
+	require.NoError(t, client.CallForInto(ctx, &out, "getBalance", nil))
+	require.GreaterOrEqual(t, time.Since(start), 900*time.Millisecond)
+	require.Equal(t, 55.0, limiter.Rate(), "成功之后逐步恢复速率")
+}
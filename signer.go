@@ -0,0 +1,257 @@
+package gosolana
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/gagliardetto/solana-go"
+)
+
+// Signer 签名者，私钥可以保存在内存、keystore文件或者远程签名服务中
+type Signer interface {
+	// PublicKey 签名者的地址
+	PublicKey() solana.PublicKey
+	// SignMessage 对交易消息等原始字节签名
+	SignMessage(ctx context.Context, message []byte) (solana.Signature, error)
+}
+
+// ErrKeyNotExportable 签名者的私钥不在本地，无法导出
+var ErrKeyNotExportable = errors.New("签名者的私钥无法导出")
+
+// MissingSignersError 交易中有需要签名的账户没有提供签名者
+type MissingSignersError struct {
+	PublicKeys []solana.PublicKey
+}
+
+func (e *MissingSignersError) Error() string {
+	keys := make([]string, len(e.PublicKeys))
+	for i, key := range e.PublicKeys {
+		keys[i] = key.String()
+	}
+	return fmt.Sprintf("缺少 %d 个账户的签名者: %s", len(keys), strings.Join(keys, ", "))
+}
+
+// KeySigner 使用内存中私钥的签名者
+type KeySigner struct {
+	key solana.PrivateKey
+}
+
+var _ Signer = &KeySigner{}
+
+// NewKeySigner 创建一个使用内存中私钥的签名者
+func NewKeySigner(key solana.PrivateKey) *KeySigner {
+	return &KeySigner{key: key}
+}
+
+func (s *KeySigner) PublicKey() solana.PublicKey {
+	return s.key.PublicKey()
+}
+
+func (s *KeySigner) SignMessage(ctx context.Context, message []byte) (solana.Signature, error) {
+	return s.key.Sign(message)
+}
+
+// String 打印签名者时只显示地址
+func (s *KeySigner) String() string {
+	return "KeySigner(" + s.PublicKey().String() + ")"
+}
+
+// GoString 防止 %#v 打印出私钥
+func (s *KeySigner) GoString() string {
+	return s.String()
+}
+
+// KeySigners 将多个私钥转换为签名者
+func KeySigners(keys ...solana.PrivateKey) []Signer {
+	signers := make([]Signer, len(keys))
+	for i, key := range keys {
+		signers[i] = NewKeySigner(key)
+	}
+	return signers
+}
+
+// RemoteSignerOptions 远程签名者的配置
+type RemoteSignerOptions struct {
+	HTTPClient *http.Client      // 默认为 http.DefaultClient
+	Headers    map[string]string // 例如签名服务的鉴权信息
+}
+
+// RemoteSigner 通过HTTP请求远程签名服务签名，私钥不会离开签名服务
+//
+// 请求为 POST {"publicKey": "<base58地址>", "message": "<base64消息>"}，
+// 响应为 {"signature": "<base58签名>"}，返回的签名会在本地校验
+type RemoteSigner struct {
+	endpoint   string
+	publicKey  solana.PublicKey
+	httpClient *http.Client
+	headers    map[string]string
+}
+
+var _ Signer = &RemoteSigner{}
+
+// NewRemoteSigner 创建一个远程签名者
+//
+//	endpoint 签名服务的地址
+//	publicKey 签名服务中私钥对应的地址
+func NewRemoteSigner(endpoint string, publicKey solana.PublicKey, opts ...RemoteSignerOptions) (*RemoteSigner, error) {
+	if err := validateURL(endpoint, "http", "https"); err != nil {
+		return nil, fmt.Errorf("无效的签名服务地址: %w", err)
+	}
+	var opt RemoteSignerOptions
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+	if opt.HTTPClient == nil {
+		opt.HTTPClient = http.DefaultClient
+	}
+	return &RemoteSigner{
+		endpoint:   endpoint,
+		publicKey:  publicKey,
+		httpClient: opt.HTTPClient,
+		headers:    opt.Headers,
+	}, nil
+}
+
+func (s *RemoteSigner) PublicKey() solana.PublicKey {
+	return s.publicKey
+}
+
+type remoteSignRequest struct {
+	PublicKey string `json:"publicKey"`
+	Message   string `json:"message"`
+}
+
+type remoteSignResponse struct {
+	Signature string `json:"signature"`
+	Error     string `json:"error,omitempty"`
+}
+
+func (s *RemoteSigner) SignMessage(ctx context.Context, message []byte) (solana.Signature, error) {
+	body, err := json.Marshal(remoteSignRequest{
+		PublicKey: s.publicKey.String(),
+		Message:   base64.StdEncoding.EncodeToString(message),
+	})
+	if err != nil {
+		return solana.Signature{}, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.endpoint, bytes.NewReader(body))
+	if err != nil {
+		return solana.Signature{}, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for k, v := range s.headers {
+		req.Header.Set(k, v)
+	}
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return solana.Signature{}, fmt.Errorf("请求签名服务失败: %w", err)
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return solana.Signature{}, fmt.Errorf("读取签名服务响应失败: %w", err)
+	}
+	var out remoteSignResponse
+	if err := json.Unmarshal(data, &out); err != nil && resp.StatusCode == http.StatusOK {
+		return solana.Signature{}, fmt.Errorf("解析签名服务响应失败: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK || out.Error != "" {
+		return solana.Signature{}, fmt.Errorf("签名服务返回错误 | %s | %s", resp.Status, out.Error)
+	}
+
+	signature, err := solana.SignatureFromBase58(out.Signature)
+	if err != nil {
+		return solana.Signature{}, fmt.Errorf("签名服务返回了无效的签名: %w", err)
+	}
+	if !signature.Verify(s.publicKey, message) {
+		return solana.Signature{}, fmt.Errorf("签名服务返回的签名与地址 %s 不匹配", s.publicKey)
+	}
+	return signature, nil
+}
+
+// SignTransaction 使用 signers 为交易中所有需要签名的账户签名
+//
+// 交易中已有的签名会被保留。签名之前会检查所有需要签名的账户，
+// 缺少签名者时返回列出所有缺少的地址的 *MissingSignersError，此时不会调用任何签名者，交易也不会被修改
+func SignTransaction(ctx context.Context, tx *solana.Transaction, signers ...Signer) error {
+	return signTransaction(ctx, tx, false, signers)
+}
+
+// PartialSignTransaction 只为 signers 中包含的账户签名，其余账户的签名留空
+//
+// 部分签名的交易可以通过 tx.ToBase64 序列化后交给其他签名者，例如手续费代付服务，
+// 对方使用 solana.TransactionFromBase64 解析后调用 SignTransaction 完成签名
+func PartialSignTransaction(ctx context.Context, tx *solana.Transaction, signers ...Signer) error {
+	return signTransaction(ctx, tx, true, signers)
+}
+
+// MissingSigners 返回交易中还没有签名的账户
+func MissingSigners(tx *solana.Transaction) []solana.PublicKey {
+	required := min(int(tx.Message.Header.NumRequiredSignatures), len(tx.Message.AccountKeys))
+	var missing []solana.PublicKey
+	for i, key := range tx.Message.AccountKeys[:required] {
+		if i >= len(tx.Signatures) || tx.Signatures[i].IsZero() {
+			missing = append(missing, key)
+		}
+	}
+	return missing
+}
+
+func signTransaction(ctx context.Context, tx *solana.Transaction, partial bool, signers []Signer) error {
+	message, err := tx.Message.MarshalBinary()
+	if err != nil {
+		return fmt.Errorf("序列化交易消息失败: %w", err)
+	}
+
+	bySigner := make(map[solana.PublicKey]Signer, len(signers))
+	for _, signer := range signers {
+		// 兼容以前 SendTransaction(ctx, instruction, nil) 的写法，nil 表示没有额外的签名者
+		if signer == nil {
+			continue
+		}
+		if _, ok := bySigner[signer.PublicKey()]; !ok {
+			bySigner[signer.PublicKey()] = signer
+		}
+	}
+
+	required := int(tx.Message.Header.NumRequiredSignatures)
+	if len(tx.Message.AccountKeys) < required {
+		return fmt.Errorf("交易需要 %d 个签名，但是只有 %d 个账户", required, len(tx.Message.AccountKeys))
+	}
+	signatures := make([]solana.Signature, required)
+	if len(tx.Signatures) == required {
+		copy(signatures, tx.Signatures)
+	}
+	var missing []solana.PublicKey
+	for i, key := range tx.Message.AccountKeys[:required] {
+		if _, ok := bySigner[key]; !ok && signatures[i].IsZero() {
+			missing = append(missing, key)
+		}
+	}
+	if len(missing) > 0 && !partial {
+		return &MissingSignersError{PublicKeys: missing}
+	}
+
+	for i, key := range tx.Message.AccountKeys[:required] {
+		signer, ok := bySigner[key]
+		if !ok {
+			continue
+		}
+		signature, err := signer.SignMessage(ctx, message)
+		if err != nil {
+			return fmt.Errorf("账户 %s 签名失败: %w", key, err)
+		}
+		signatures[i] = signature
+	}
+	tx.Signatures = signatures
+	return nil
+}
@@ -0,0 +1,226 @@
+package gosolana
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/gagliardetto/solana-go"
+	"github.com/gagliardetto/solana-go/programs/system"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeSigner 进程内的签名者，记录签名次数
+type fakeSigner struct {
+	key   solana.PrivateKey
+	calls atomic.Int64
+}
+
+func newFakeSigner() *fakeSigner {
+	return &fakeSigner{key: solana.NewWallet().PrivateKey}
+}
+
+func (s *fakeSigner) PublicKey() solana.PublicKey {
+	return s.key.PublicKey()
+}
+
+func (s *fakeSigner) SignMessage(ctx context.Context, message []byte) (solana.Signature, error) {
+	s.calls.Add(1)
+	return s.key.Sign(message)
+}
+
+// newTxNode 模拟发送交易需要的RPC方法，sent 记录节点收到的交易
+func newTxNode(t *testing.T) (*testNode, *atomic.Pointer[solana.Transaction]) {
+	node := newTestNode(t, 1)
+	sent := &atomic.Pointer[solana.Transaction]{}
+	node.handler = func(w http.ResponseWriter, req map[string]any) bool {
+		switch req["method"] {
+		case "getLatestBlockhash":
+			writeResult(w, req["id"], map[string]any{
+				"context": map[string]any{"slot": 1},
+				"value": map[string]any{
+					"blockhash":            solana.Hash{1}.String(),
+					"lastValidBlockHeight": 100,
+				},
+			})
+		case "sendTransaction":
+			// 处理请求的 goroutine 中只能使用 assert，失败时返回错误让客户端也失败
+			raw, err := base64.StdEncoding.DecodeString(req["params"].([]any)[0].(string))
+			if !assert.NoError(t, err) {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return true
+			}
+			tx, err := solana.TransactionFromBytes(raw)
+			if !assert.NoError(t, err) {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return true
+			}
+			sent.Store(tx)
+			writeResult(w, req["id"], tx.Signatures[0].String())
+		case "getBlockHeight":
+			writeResult(w, req["id"], 1)
+		case "getSignatureStatuses":
+			writeResult(w, req["id"], map[string]any{
+				"context": map[string]any{"slot": 1},
+				"value": []any{map[string]any{
+					"slot":               1,
+					"confirmations":      nil,
+					"err":                nil,
+					"confirmationStatus": "confirmed",
+				}},
+			})
+		default:
+			return false
+		}
+		return true
+	}
+	return node, sent
+}
+
+func Test_WalletExternalSigner(t *testing.T) {
+	ctx := context.Background()
+	node, sent := newTxNode(t)
+	payer := newFakeSigner()
+	wallet, err := NewWallet(ctx, Option{RpcUrl: node.URL, WsMode: WsDisabled, Signer: payer})
+	require.NoError(t, err)
+	require.Equal(t, payer.PublicKey(), wallet.PublicKey())
+	_, err = wallet.ExportPrivateKey()
+	require.ErrorIs(t, err, ErrKeyNotExportable)
+
+	// 新创建的账户需要同时签名
+	account := NewKeySigner(solana.NewWallet().PrivateKey)
+	ok, err := wallet.SendTransaction(ctx, []solana.Instruction{
+		system.NewCreateAccountInstruction(1, 0, solana.SystemProgramID, payer.PublicKey(), account.PublicKey()).Build(),
+	}, account)
+	require.NoError(t, err)
+	require.True(t, ok)
+	require.Equal(t, int64(1), payer.calls.Load())
+
+	tx := sent.Load()
+	require.NotNil(t, tx)
+	require.NoError(t, tx.VerifySignatures())
+	require.Len(t, tx.Signatures, 2)
+
+	// nil 签名者会被忽略
+	ok, err = wallet.SendTransaction(ctx, []solana.Instruction{
+		system.NewTransferInstruction(1, payer.PublicKey(), solana.NewWallet().PublicKey()).Build(),
+	}, nil)
+	require.NoError(t, err)
+	require.True(t, ok)
+	require.NoError(t, sent.Load().VerifySignatures())
+}
+
+func Test_RemoteSigner(t *testing.T) {
+	ctx := context.Background()
+	key := solana.NewWallet().PrivateKey
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req remoteSignRequest
+		if !assert.Equal(t, "secret", r.Header.Get("Authorization")) ||
+			!assert.NoError(t, json.NewDecoder(r.Body).Decode(&req)) ||
+			!assert.Equal(t, key.PublicKey().String(), req.PublicKey) {
+			http.Error(w, "bad request", http.StatusBadRequest)
+			return
+		}
+		message, err := base64.StdEncoding.DecodeString(req.Message)
+		if !assert.NoError(t, err) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		signature := key.Sign
+		if string(message) == "wrong" {
+			signature = solana.NewWallet().PrivateKey.Sign
+		}
+		sig, err := signature(message)
+		if !assert.NoError(t, err) {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		json.NewEncoder(w).Encode(remoteSignResponse{Signature: sig.String()})
+	}))
+	defer server.Close()
+
+	signer, err := NewRemoteSigner(server.URL, key.PublicKey(), RemoteSignerOptions{
+		Headers: map[string]string{"Authorization": "secret"},
+	})
+	require.NoError(t, err)
+
+	sig, err := signer.SignMessage(ctx, []byte("hello"))
+	require.NoError(t, err)
+	require.True(t, sig.Verify(key.PublicKey(), []byte("hello")))
+
+	_, err = signer.SignMessage(ctx, []byte("wrong"))
+	require.Error(t, err, "签名服务返回的签名需要在本地校验")
+}
+
+func Test_MissingSigners(t *testing.T) {
+	ctx := context.Background()
+	node, sent := newTxNode(t)
+	payer := newFakeSigner()
+	wallet, err := NewWallet(ctx, Option{RpcUrl: node.URL, WsMode: WsDisabled, Signer: payer})
+	require.NoError(t, err)
+
+	first, second := solana.NewWallet().PrivateKey, solana.NewWallet().PrivateKey
+	instructions := []solana.Instruction{
+		system.NewCreateAccountInstruction(1, 0, solana.SystemProgramID, payer.PublicKey(), first.PublicKey()).Build(),
+		system.NewCreateAccountInstruction(1, 0, solana.SystemProgramID, payer.PublicKey(), second.PublicKey()).Build(),
+	}
+	_, err = wallet.SendTransaction(ctx, instructions, KeySigners(first)...)
+	var missing *MissingSignersError
+	require.ErrorAs(t, err, &missing)
+	require.Equal(t, []solana.PublicKey{second.PublicKey()}, missing.PublicKeys)
+	require.Contains(t, err.Error(), second.PublicKey().String())
+	require.Zero(t, payer.calls.Load(), "缺少签名者时不应该签名")
+	require.Nil(t, sent.Load(), "缺少签名者时不应该发送交易")
+
+	ok, err := wallet.SendTransaction(ctx, instructions, KeySigners(first, second)...)
+	require.NoError(t, err)
+	require.True(t, ok)
+	require.NoError(t, sent.Load().VerifySignatures())
+}
+
+func Test_FeePayer(t *testing.T) {
+	ctx := context.Background()
+	node, sent := newTxNode(t)
+	user := newFakeSigner()
+	wallet, err := NewWallet(ctx, Option{RpcUrl: node.URL, WsMode: WsDisabled, Signer: user})
+	require.NoError(t, err)
+	recipient := solana.NewWallet().PublicKey()
+	transfer := []solana.Instruction{system.NewTransferInstruction(1, user.PublicKey(), recipient).Build()}
+
+	// 本地的手续费支付者
+	payer := newFakeSigner()
+	ok, err := wallet.SendTransactionWithOpts(ctx, transfer, SendOptions{FeePayer: payer})
+	require.NoError(t, err)
+	require.True(t, ok)
+	require.Equal(t, payer.PublicKey(), sent.Load().Message.AccountKeys[0])
+	require.NoError(t, sent.Load().VerifySignatures())
+
+	// 部分签名之后交给手续费代付服务
+	sponsor := newFakeSigner()
+	tx, err := wallet.NewTransaction(ctx, transfer, sponsor.PublicKey())
+	require.NoError(t, err)
+	require.NoError(t, wallet.PartialSignTransaction(ctx, tx))
+	require.Equal(t, []solana.PublicKey{sponsor.PublicKey()}, MissingSigners(tx))
+	_, err = wallet.SendSignedTransaction(ctx, tx)
+	var missing *MissingSignersError
+	require.ErrorAs(t, err, &missing)
+
+	encoded, err := tx.ToBase64()
+	require.NoError(t, err)
+	received, err := solana.TransactionFromBase64(encoded)
+	require.NoError(t, err)
+	require.NoError(t, SignTransaction(ctx, received, sponsor))
+	require.Empty(t, MissingSigners(received))
+	require.Equal(t, tx.Signatures[1], received.Signatures[1], "用户的签名应该被保留")
+
+	ok, err = wallet.SendSignedTransaction(ctx, received)
+	require.NoError(t, err)
+	require.True(t, ok)
+	require.NoError(t, sent.Load().VerifySignatures())
+}
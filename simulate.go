@@ -0,0 +1,57 @@
+package gosolana
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/gagliardetto/solana-go"
+	"github.com/gagliardetto/solana-go/rpc"
+	"github.com/go-enols/gosolana/log"
+)
+
+// SimulationError 模拟交易执行失败，Logs 为程序输出的日志
+type SimulationError struct {
+	Err  any
+	Logs []string
+}
+
+func (e *SimulationError) Error() string {
+	if len(e.Logs) == 0 {
+		return fmt.Sprintf("模拟交易失败: %v", e.Err)
+	}
+	return fmt.Sprintf("模拟交易失败: %v\n%s", e.Err, strings.Join(e.Logs, "\n"))
+}
+
+// SimulateComputeUnits 模拟执行交易并返回消耗的计算单元
+//
+// 模拟时不校验签名并使用最新的区块哈希，因此交易可以没有签名，区块哈希也可以已经过期。
+// 交易执行失败时返回 *SimulationError
+func (w *Wallet) SimulateComputeUnits(ctx context.Context, tx *solana.Transaction) (uint64, error) {
+	// 未签名的交易使用空签名占位，否则节点无法解析交易
+	simulated := *tx
+	if required := int(tx.Message.Header.NumRequiredSignatures); len(tx.Signatures) != required {
+		simulated.Signatures = make([]solana.Signature, required)
+	}
+	out, err := w.GetClient().SimulateTransactionWithOpts(ctx, &simulated, &rpc.SimulateTransactionOpts{
+		SigVerify:              false,
+		ReplaceRecentBlockhash: true,
+		Commitment:             rpc.CommitmentProcessed,
+	})
+	if err != nil {
+		log.Error("模拟交易失败", "error", err)
+		return 0, err
+	}
+	if out.Value == nil {
+		return 0, errors.New("模拟交易没有返回结果")
+	}
+	if out.Value.Err != nil {
+		log.Warn("模拟交易执行失败", "error", out.Value.Err, "logs", out.Value.Logs)
+		return 0, &SimulationError{Err: out.Value.Err, Logs: out.Value.Logs}
+	}
+	if out.Value.UnitsConsumed == nil || *out.Value.UnitsConsumed == 0 {
+		return 0, errors.New("节点没有返回消耗的计算单元")
+	}
+	return *out.Value.UnitsConsumed, nil
+}
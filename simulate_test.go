@@ -0,0 +1,101 @@
+package gosolana
+
+import (
+	"context"
+	"encoding/base64"
+	"net/http"
+	"testing"
+
+	"github.com/gagliardetto/solana-go"
+	computebudget "github.com/gagliardetto/solana-go/programs/compute-budget"
+	"github.com/gagliardetto/solana-go/programs/system"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// withSimulation 让测试节点处理 simulateTransaction，返回固定的计算单元或者错误
+func withSimulation(t *testing.T, node *testNode, units uint64, txErr any) {
+	next := node.handler
+	node.handler = func(w http.ResponseWriter, req map[string]any) bool {
+		if req["method"] != "simulateTransaction" {
+			return next(w, req)
+		}
+		if !checkSimulation(t, req["params"].([]any)) {
+			http.Error(w, "unexpected simulateTransaction request", http.StatusBadRequest)
+			return true
+		}
+		writeResult(w, req["id"], map[string]any{
+			"context": map[string]any{"slot": 1},
+			"value": map[string]any{
+				"err":           txErr,
+				"logs":          []string{"Program 11111111111111111111111111111111 invoke [1]"},
+				"unitsConsumed": units,
+			},
+		})
+		return true
+	}
+}
+
+// checkSimulation 检查模拟请求的参数，在处理请求的 goroutine 中调用，因此只能使用 assert
+func checkSimulation(t *testing.T, params []any) bool {
+	config := params[1].(map[string]any)
+	if !assert.Equal(t, true, config["replaceRecentBlockhash"]) || !assert.NotEqual(t, true, config["sigVerify"]) {
+		return false
+	}
+	raw, err := base64.StdEncoding.DecodeString(params[0].(string))
+	if !assert.NoError(t, err) {
+		return false
+	}
+	tx, err := solana.TransactionFromBytes(raw)
+	if !assert.NoError(t, err) {
+		return false
+	}
+	decoded, err := computebudget.DecodeInstruction(nil, tx.Message.Instructions[0].Data)
+	if !assert.NoError(t, err) {
+		return false
+	}
+	limit, ok := decoded.Impl.(*computebudget.SetComputeUnitLimit)
+	return assert.True(t, ok, "第一条指令应该是 SetComputeUnitLimit") &&
+		assert.Equal(t, uint32(MaxComputeUnitLimit), limit.Units)
+}
+
+func Test_EstimateComputeUnits(t *testing.T) {
+	ctx := context.Background()
+	node, sent := newTxNode(t)
+	withSimulation(t, node, 1000, nil)
+	wallet, err := NewWallet(ctx, Option{RpcUrl: node.URL, WsMode: WsDisabled})
+	require.NoError(t, err)
+	transfer := []solana.Instruction{system.NewTransferInstruction(1, wallet.PublicKey(), solana.NewWallet().PublicKey()).Build()}
+
+	ok, err := wallet.SendTransactionWithOpts(ctx, transfer, SendOptions{EstimateComputeUnits: true, ComputeUnitMargin: 0.2})
+	require.NoError(t, err)
+	require.True(t, ok)
+	tx := sent.Load()
+	require.NoError(t, tx.VerifySignatures())
+	decoded, err := computebudget.DecodeInstruction(nil, tx.Message.Instructions[0].Data)
+	require.NoError(t, err)
+	require.Equal(t, uint32(1200), decoded.Impl.(*computebudget.SetComputeUnitLimit).Units)
+
+	// 估算会覆盖手动设置的上限
+	built, err := wallet.NewTxBuilder().AddInstruction(transfer...).SetComputeUnitLimit(5).EstimateComputeUnits().Build(ctx)
+	require.NoError(t, err)
+	require.Len(t, built.Message.Instructions, 2)
+	decoded, err = computebudget.DecodeInstruction(nil, built.Message.Instructions[0].Data)
+	require.NoError(t, err)
+	require.Equal(t, uint32(1100), decoded.Impl.(*computebudget.SetComputeUnitLimit).Units)
+}
+
+func Test_EstimateComputeUnitsFailed(t *testing.T) {
+	ctx := context.Background()
+	node, sent := newTxNode(t)
+	withSimulation(t, node, 300, map[string]any{"InstructionError": []any{1, map[string]any{"Custom": 1}}})
+	wallet, err := NewWallet(ctx, Option{RpcUrl: node.URL, WsMode: WsDisabled})
+	require.NoError(t, err)
+	transfer := []solana.Instruction{system.NewTransferInstruction(1, wallet.PublicKey(), solana.NewWallet().PublicKey()).Build()}
+
+	_, err = wallet.SendTransactionWithOpts(ctx, transfer, SendOptions{EstimateComputeUnits: true})
+	var simErr *SimulationError
+	require.ErrorAs(t, err, &simErr)
+	require.NotEmpty(t, simErr.Logs)
+	require.Nil(t, sent.Load(), "模拟失败时不应该发送交易")
+}
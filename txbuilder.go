@@ -0,0 +1,291 @@
+package gosolana
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+
+	bin "github.com/gagliardetto/binary"
+	"github.com/gagliardetto/solana-go"
+	computebudget "github.com/gagliardetto/solana-go/programs/compute-budget"
+	"github.com/gagliardetto/solana-go/rpc"
+	"github.com/go-enols/gosolana/log"
+)
+
+// MaxTransactionSize 序列化之后交易的最大字节数，即 IPv6 最小MTU 1280 减去48字节的头部
+const MaxTransactionSize = 1232
+
+const (
+	// MaxComputeUnitLimit 单个交易可以设置的最大计算单元
+	MaxComputeUnitLimit = 1_400_000
+	// DefaultComputeUnitMargin 模拟交易之后在消耗的计算单元上增加的比例
+	DefaultComputeUnitMargin = 0.1
+)
+
+// ErrDuplicateComputeBudget 指令中已经包含了 TxBuilder 要添加的 ComputeBudget 指令
+var ErrDuplicateComputeBudget = errors.New("重复的ComputeBudget指令")
+
+// TransactionTooLargeError 交易超过了 MaxTransactionSize
+type TransactionTooLargeError struct {
+	Size int
+}
+
+func (e *TransactionTooLargeError) Error() string {
+	return fmt.Sprintf("交易大小为 %d 字节，超过了 %d 字节的限制", e.Size, MaxTransactionSize)
+}
+
+// TxBuilder 构造交易，自动添加 ComputeBudget 指令并检查交易大小
+//
+//	tx, err := wallet.NewTxBuilder().
+//		AddInstruction(ins...).
+//		SetComputeUnitLimit(200_000).
+//		SetComputeUnitPrice(10_000).
+//		Build(ctx)
+type TxBuilder struct {
+	wallet       *Wallet
+	instructions []solana.Instruction
+	feePayer     Signer
+	signers      []Signer
+	blockhash    solana.Hash
+
+	computeUnitLimit uint32
+	computeUnitPrice uint64
+	estimate         bool
+	margin           float64
+	priorityFee      *PriorityFeeOptions
+
+	lastValidBlockHeight uint64
+}
+
+// NewTxBuilder 创建交易构造器，默认由钱包支付手续费并签名
+func (w *Wallet) NewTxBuilder() *TxBuilder {
+	return &TxBuilder{wallet: w}
+}
+
+// AddInstruction 添加指令
+func (b *TxBuilder) AddInstruction(instruction ...solana.Instruction) *TxBuilder {
+	b.instructions = append(b.instructions, instruction...)
+	return b
+}
+
+// SetFeePayer 设置支付手续费的签名者，默认为钱包本身
+func (b *TxBuilder) SetFeePayer(feePayer Signer) *TxBuilder {
+	b.feePayer = feePayer
+	return b
+}
+
+// AddSigners 添加指令需要的其他签名者，例如新创建的账户
+func (b *TxBuilder) AddSigners(signers ...Signer) *TxBuilder {
+	b.signers = append(b.signers, signers...)
+	return b
+}
+
+// SetRecentBlockhash 设置交易的区块哈希，不设置时 Build 会获取最新的区块哈希
+//
+//	lastValidBlockHeight 区块哈希的最后有效区块高度（可选），用于 Send 判断交易是否过期
+func (b *TxBuilder) SetRecentBlockhash(blockhash solana.Hash, lastValidBlockHeight ...uint64) *TxBuilder {
+	b.blockhash = blockhash
+	b.lastValidBlockHeight = 0
+	if len(lastValidBlockHeight) > 0 {
+		b.lastValidBlockHeight = lastValidBlockHeight[0]
+	}
+	return b
+}
+
+// LastValidBlockHeight 上一次 Build 使用的区块哈希的最后有效区块高度，未知时为0
+func (b *TxBuilder) LastValidBlockHeight() uint64 {
+	return b.lastValidBlockHeight
+}
+
+// SetComputeUnitLimit 设置交易的计算单元上限，为0时不添加 SetComputeUnitLimit 指令
+func (b *TxBuilder) SetComputeUnitLimit(units uint32) *TxBuilder {
+	b.computeUnitLimit = units
+	return b
+}
+
+// SetComputeUnitPrice 设置每个计算单元的价格（单位为 micro-lamports），即优先费，为0时不添加 SetComputeUnitPrice 指令
+func (b *TxBuilder) SetComputeUnitPrice(microLamports uint64) *TxBuilder {
+	b.computeUnitPrice = microLamports
+	return b
+}
+
+// EstimateComputeUnits 在 Build 时先模拟交易，把计算单元上限设置为实际消耗的计算单元加上 margin 的比例，
+// 会覆盖 SetComputeUnitLimit 设置的值
+//
+//	margin 默认为 DefaultComputeUnitMargin
+func (b *TxBuilder) EstimateComputeUnits(margin ...float64) *TxBuilder {
+	b.estimate = true
+	b.margin = DefaultComputeUnitMargin
+	if len(margin) > 0 {
+		b.margin = margin[0]
+	}
+	return b
+}
+
+// EstimatePriorityFee 在 Build 时根据指令中的可写账户估算优先费，会覆盖 SetComputeUnitPrice 设置的值，见 Wallet.EstimatePriorityFee
+func (b *TxBuilder) EstimatePriorityFee(opts ...PriorityFeeOptions) *TxBuilder {
+	b.priorityFee = &PriorityFeeOptions{}
+	if len(opts) > 0 {
+		b.priorityFee = &opts[0]
+	}
+	return b
+}
+
+// Build 构造未签名的交易，ComputeBudget 指令放在最前面，交易超过 MaxTransactionSize 时返回 *TransactionTooLargeError
+func (b *TxBuilder) Build(ctx context.Context) (*solana.Transaction, error) {
+	price := b.computeUnitPrice
+	if b.priorityFee != nil && len(b.instructions) > 0 {
+		fee, err := b.wallet.EstimatePriorityFee(ctx, b.instructions, *b.priorityFee)
+		if err != nil {
+			return nil, err
+		}
+		log.Debug("估算的优先费", "microLamports", fee)
+		price = fee
+	}
+	instructions, err := b.allInstructions(price)
+	if err != nil {
+		return nil, err
+	}
+	blockhash := b.blockhash
+	if blockhash.IsZero() {
+		recent, err := b.wallet.GetClient().GetLatestBlockhash(ctx, rpc.CommitmentFinalized)
+		if err != nil {
+			log.Error("获取Hash失败", "error", err)
+			return nil, err
+		}
+		blockhash = recent.Value.Blockhash
+		b.lastValidBlockHeight = recent.Value.LastValidBlockHeight
+	}
+
+	if b.estimate {
+		limit, err := b.estimateLimit(ctx, instructions, blockhash)
+		if err != nil {
+			return nil, err
+		}
+		instructions = setComputeUnitLimit(instructions, limit)
+	}
+
+	tx, err := solana.NewTransaction(instructions, blockhash, solana.TransactionPayer(b.payer().PublicKey()))
+	if err != nil {
+		log.Error("构建交易失败", "error", err)
+		return nil, err
+	}
+	size, err := TransactionSize(tx)
+	if err != nil {
+		return nil, err
+	}
+	if size > MaxTransactionSize {
+		return nil, &TransactionTooLargeError{Size: size}
+	}
+	return tx, nil
+}
+
+// BuildAndSign 构造交易并使用钱包、手续费支付者和其他签名者签名
+func (b *TxBuilder) BuildAndSign(ctx context.Context) (*solana.Transaction, error) {
+	tx, err := b.Build(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err := b.wallet.SignTransaction(ctx, tx, b.allSigners()...); err != nil {
+		log.Error("签名交易失败", "error", err)
+		return nil, err
+	}
+	return tx, nil
+}
+
+// Send 构造、签名并发送交易，等待交易确认，区块哈希过期时返回 ErrBlockhashExpired，可以重新调用 Send
+//
+//	opts 确认交易的配置，见 SendOptions
+func (b *TxBuilder) Send(ctx context.Context, opts ...SendOptions) (bool, error) {
+	tx, err := b.BuildAndSign(ctx)
+	if err != nil {
+		return false, err
+	}
+	opt := SendOptions{}
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+	opt.LastValidBlockHeight = b.lastValidBlockHeight
+	return b.wallet.SendSignedTransactionWithOpts(ctx, tx, opt)
+}
+
+func (b *TxBuilder) payer() Signer {
+	if b.feePayer != nil {
+		return b.feePayer
+	}
+	return b.wallet.signer
+}
+
+func (b *TxBuilder) allSigners() []Signer {
+	if b.feePayer == nil {
+		return b.signers
+	}
+	return append([]Signer{b.feePayer}, b.signers...)
+}
+
+// allInstructions 在指令前面加上 ComputeBudget 指令，同一种指令出现两次时交易会执行失败，因此提前返回错误
+func (b *TxBuilder) allInstructions(price uint64) ([]solana.Instruction, error) {
+	if len(b.instructions) == 0 {
+		return nil, errors.New("交易至少需要一条指令")
+	}
+	var budget []solana.Instruction
+	if b.estimate {
+		// 模拟时使用最大的上限，模拟之后再替换为实际的值
+		budget = append(budget, computebudget.NewSetComputeUnitLimitInstruction(MaxComputeUnitLimit).Build())
+	} else if b.computeUnitLimit > 0 {
+		budget = append(budget, computebudget.NewSetComputeUnitLimitInstruction(b.computeUnitLimit).Build())
+	}
+	if price > 0 {
+		budget = append(budget, computebudget.NewSetComputeUnitPriceInstruction(price).Build())
+	}
+	for _, instruction := range b.instructions {
+		if !instruction.ProgramID().Equals(solana.ComputeBudget) {
+			continue
+		}
+		data, err := instruction.Data()
+		if err != nil || len(data) == 0 {
+			continue
+		}
+		if (data[0] == computebudget.Instruction_SetComputeUnitLimit && (b.computeUnitLimit > 0 || b.estimate)) ||
+			(data[0] == computebudget.Instruction_SetComputeUnitPrice && (price > 0 || b.priorityFee != nil)) {
+			return nil, fmt.Errorf("%w: %s", ErrDuplicateComputeBudget, computebudget.InstructionIDToName(data[0]))
+		}
+	}
+	return append(budget, b.instructions...), nil
+}
+
+// estimateLimit 模拟交易，返回消耗的计算单元加上 margin 之后的上限
+func (b *TxBuilder) estimateLimit(ctx context.Context, instructions []solana.Instruction, blockhash solana.Hash) (uint32, error) {
+	tx, err := solana.NewTransaction(instructions, blockhash, solana.TransactionPayer(b.payer().PublicKey()))
+	if err != nil {
+		log.Error("构建交易失败", "error", err)
+		return 0, err
+	}
+	units, err := b.wallet.SimulateComputeUnits(ctx, tx)
+	if err != nil {
+		return 0, err
+	}
+	limit := min(uint64(math.Ceil(float64(units)*(1+max(b.margin, 0)))), MaxComputeUnitLimit)
+	log.Debug("模拟交易消耗的计算单元", "units", units, "limit", limit)
+	return uint32(limit), nil
+}
+
+// setComputeUnitLimit 替换 allInstructions 放在第一条的 SetComputeUnitLimit 指令
+func setComputeUnitLimit(instructions []solana.Instruction, limit uint32) []solana.Instruction {
+	replaced := append([]solana.Instruction{}, instructions...)
+	replaced[0] = computebudget.NewSetComputeUnitLimitInstruction(limit).Build()
+	return replaced
+}
+
+// TransactionSize 交易序列化之后的字节数，未签名的交易按照需要的签名数量计算
+func TransactionSize(tx *solana.Transaction) (int, error) {
+	message, err := tx.Message.MarshalBinary()
+	if err != nil {
+		return 0, fmt.Errorf("序列化交易消息失败: %w", err)
+	}
+	signatures := max(len(tx.Signatures), int(tx.Message.Header.NumRequiredSignatures))
+	var count []byte
+	bin.EncodeCompactU16Length(&count, signatures)
+	return len(count) + signatures*64 + len(message), nil
+}
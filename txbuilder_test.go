@@ -0,0 +1,85 @@
+package gosolana
+
+import (
+	"context"
+	"testing"
+
+	"github.com/gagliardetto/solana-go"
+	computebudget "github.com/gagliardetto/solana-go/programs/compute-budget"
+	"github.com/gagliardetto/solana-go/programs/memo"
+	"github.com/gagliardetto/solana-go/programs/system"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_TxBuilder(t *testing.T) {
+	ctx := context.Background()
+	node, sent := newTxNode(t)
+	wallet, err := NewWallet(ctx, Option{RpcUrl: node.URL, WsMode: WsDisabled})
+	require.NoError(t, err)
+	payer := newFakeSigner()
+	account := NewKeySigner(solana.NewWallet().PrivateKey)
+
+	ok, err := wallet.NewTxBuilder().
+		AddInstruction(system.NewCreateAccountInstruction(1, 0, solana.SystemProgramID, wallet.PublicKey(), account.PublicKey()).Build()).
+		SetFeePayer(payer).
+		AddSigners(account).
+		SetComputeUnitLimit(50_000).
+		SetComputeUnitPrice(1_000).
+		Send(ctx)
+	require.NoError(t, err)
+	require.True(t, ok)
+
+	tx := sent.Load()
+	require.NoError(t, tx.VerifySignatures())
+	require.Equal(t, payer.PublicKey(), tx.Message.AccountKeys[0])
+	require.Equal(t, solana.Hash{1}, tx.Message.RecentBlockhash)
+	require.Len(t, tx.Message.Instructions, 3)
+
+	limit, err := tx.Message.Program(tx.Message.Instructions[0].ProgramIDIndex)
+	require.NoError(t, err)
+	require.Equal(t, solana.ComputeBudget, limit)
+	decoded, err := computebudget.DecodeInstruction(nil, tx.Message.Instructions[0].Data)
+	require.NoError(t, err)
+	require.Equal(t, uint32(50_000), decoded.Impl.(*computebudget.SetComputeUnitLimit).Units)
+	decoded, err = computebudget.DecodeInstruction(nil, tx.Message.Instructions[1].Data)
+	require.NoError(t, err)
+	require.Equal(t, uint64(1_000), decoded.Impl.(*computebudget.SetComputeUnitPrice).MicroLamports)
+}
+
+func Test_TxBuilderErrors(t *testing.T) {
+	ctx := context.Background()
+	node := newTestNode(t, 1)
+	wallet, err := NewWallet(ctx, Option{RpcUrl: node.URL, WsMode: WsDisabled})
+	require.NoError(t, err)
+	blockhash := solana.Hash{1}
+
+	_, err = wallet.NewTxBuilder().SetRecentBlockhash(blockhash).Build(ctx)
+	require.Error(t, err)
+
+	_, err = wallet.NewTxBuilder().
+		SetRecentBlockhash(blockhash).
+		AddInstruction(computebudget.NewSetComputeUnitLimitInstruction(1).Build()).
+		SetComputeUnitLimit(2).
+		Build(ctx)
+	require.ErrorIs(t, err, ErrDuplicateComputeBudget)
+
+	// 没有设置价格时可以自己添加 SetComputeUnitPrice 指令
+	tx, err := wallet.NewTxBuilder().
+		SetRecentBlockhash(blockhash).
+		AddInstruction(computebudget.NewSetComputeUnitPriceInstruction(1).Build()).
+		SetComputeUnitLimit(2).
+		Build(ctx)
+	require.NoError(t, err)
+	size, err := TransactionSize(tx)
+	require.NoError(t, err)
+	require.NoError(t, wallet.SignTransaction(ctx, tx))
+	raw, err := tx.MarshalBinary()
+	require.NoError(t, err)
+	require.Len(t, raw, size)
+
+	memoIns := memo.NewMemoInstruction(make([]byte, 1200), wallet.PublicKey()).Build()
+	_, err = wallet.NewTxBuilder().SetRecentBlockhash(blockhash).AddInstruction(memoIns).Build(ctx)
+	var tooLarge *TransactionTooLargeError
+	require.ErrorAs(t, err, &tooLarge)
+	require.Greater(t, tooLarge.Size, MaxTransactionSize)
+}
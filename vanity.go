@@ -0,0 +1,243 @@
+package gosolana
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"math"
+	"runtime"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/gagliardetto/solana-go"
+	"github.com/mr-tron/base58"
+)
+
+// base58Alphabet 比特币和 Solana 使用的base58字符集，不包含 0、O、I、l
+const base58Alphabet = "123456789ABCDEFGHJKLMNPQRSTUVWXYZabcdefghijkmnopqrstuvwxyz"
+
+// ErrInvalidPattern 靓号地址的搜索条件无效
+var ErrInvalidPattern = errors.New("无效的靓号地址条件")
+
+// VanityOptions 靓号地址的搜索条件
+type VanityOptions struct {
+	Prefix     string // 地址前缀
+	Suffix     string // 地址后缀
+	IgnoreCase bool   // 不区分大小写
+
+	Workers          int                  // 并行搜索的goroutine数量，默认为CPU核心数
+	Progress         func(VanityProgress) // 定期回调搜索进度（可选）
+	ProgressInterval time.Duration        // 回调进度的间隔，默认1秒
+}
+
+// VanityProgress 搜索进度
+type VanityProgress struct {
+	Attempts uint64        // 已经尝试的次数
+	Expected float64       // 预计需要尝试的次数，见 ExpectedVanityAttempts
+	Elapsed  time.Duration // 已经花费的时间
+	Rate     float64       // 每秒尝试的次数
+}
+
+// Remaining 按照当前速度估算的剩余时间，找到地址之前实际需要的时间是随机的
+func (p VanityProgress) Remaining() time.Duration {
+	if p.Rate <= 0 || float64(p.Attempts) >= p.Expected {
+		return 0
+	}
+	remaining := (p.Expected - float64(p.Attempts)) / p.Rate * float64(time.Second)
+	if remaining >= math.MaxInt64 {
+		return math.MaxInt64
+	}
+	return time.Duration(remaining)
+}
+
+// VanityResult 找到的靓号地址
+type VanityResult struct {
+	PrivateKey solana.PrivateKey
+	Attempts   uint64
+	Elapsed    time.Duration
+}
+
+// PublicKey 靓号地址
+func (r *VanityResult) PublicKey() solana.PublicKey {
+	return r.PrivateKey.PublicKey()
+}
+
+// Pkey 返回base58格式的私钥，可以直接用作 Option.Pkey
+func (r *VanityResult) Pkey() string {
+	return r.PrivateKey.String()
+}
+
+// String 打印结果时隐藏私钥
+func (r *VanityResult) String() string {
+	return fmt.Sprintf("VanityResult(%s, %d attempts, %s)", r.PublicKey(), r.Attempts, r.Elapsed)
+}
+
+// GoString 防止 %#v 打印出私钥
+func (r *VanityResult) GoString() string {
+	return r.String()
+}
+
+// ExpectedVanityAttempts 预计需要尝试的次数，即匹配概率的倒数
+//
+// 按照地址的每个字符在base58字符集中均匀分布估算，地址的第一个字符实际上并不均匀，因此只是一个近似值
+func ExpectedVanityAttempts(opts VanityOptions) float64 {
+	expected := 1.0
+	for _, c := range opts.Prefix + opts.Suffix {
+		expected *= float64(len(base58Alphabet)) / float64(len(vanityVariants(c, opts.IgnoreCase)))
+	}
+	return expected
+}
+
+// vanityVariants 返回字符在地址中可以匹配的base58字符
+func vanityVariants(c rune, ignoreCase bool) []rune {
+	if c >= utf8.RuneSelf {
+		return nil
+	}
+	candidates := []rune{c}
+	if lower, upper := unicode.ToLower(c), unicode.ToUpper(c); ignoreCase && lower != upper {
+		candidates = []rune{lower, upper}
+	}
+	var variants []rune
+	for _, v := range candidates {
+		if strings.ContainsRune(base58Alphabet, v) {
+			variants = append(variants, v)
+		}
+	}
+	return variants
+}
+
+func (opts VanityOptions) validate() error {
+	if opts.Prefix == "" && opts.Suffix == "" {
+		return fmt.Errorf("%w: 至少需要设置前缀或者后缀", ErrInvalidPattern)
+	}
+	for _, c := range opts.Prefix + opts.Suffix {
+		if len(vanityVariants(c, opts.IgnoreCase)) == 0 {
+			return fmt.Errorf("%w: %q 不是base58字符，地址中不会出现 0、O、I、l", ErrInvalidPattern, c)
+		}
+	}
+	// 公钥为32个字节，base58编码之后最多44个字符
+	if len(opts.Prefix)+len(opts.Suffix) > 44 {
+		return fmt.Errorf("%w: 前缀和后缀的总长度不能超过44个字符", ErrInvalidPattern)
+	}
+	return nil
+}
+
+func (opts VanityOptions) match(address string) bool {
+	if len(address) < len(opts.Prefix)+len(opts.Suffix) {
+		return false
+	}
+	prefix, suffix := address[:len(opts.Prefix)], address[len(address)-len(opts.Suffix):]
+	if opts.IgnoreCase {
+		return strings.EqualFold(prefix, opts.Prefix) && strings.EqualFold(suffix, opts.Suffix)
+	}
+	return prefix == opts.Prefix && suffix == opts.Suffix
+}
+
+// GenerateVanity 使用所有CPU核心搜索匹配前缀和后缀的地址，直到找到或者 ctx 被取消
+//
+// 每增加一个字符需要尝试的次数大约增加58倍，开始之前可以使用 ExpectedVanityAttempts 估算
+func GenerateVanity(ctx context.Context, opts VanityOptions) (*VanityResult, error) {
+	if err := opts.validate(); err != nil {
+		return nil, err
+	}
+	if opts.Workers <= 0 {
+		opts.Workers = runtime.NumCPU()
+	}
+	if opts.ProgressInterval <= 0 {
+		opts.ProgressInterval = time.Second
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var (
+		attempts atomic.Uint64
+		start    = time.Now()
+		found    = make(chan solana.PrivateKey, 1)
+		wg       sync.WaitGroup
+	)
+	for i := 0; i < opts.Workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			searchVanity(ctx, opts, &attempts, found)
+		}()
+	}
+	if opts.Progress != nil {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			reportVanity(ctx, opts, &attempts, start)
+		}()
+	}
+
+	var (
+		key solana.PrivateKey
+		err error
+	)
+	select {
+	case key = <-found:
+	case <-ctx.Done():
+		err = ctx.Err()
+	}
+	cancel()
+	wg.Wait()
+	if err != nil {
+		return nil, err
+	}
+	return &VanityResult{PrivateKey: key, Attempts: attempts.Load(), Elapsed: time.Since(start)}, nil
+}
+
+// vanityBatch 每个goroutine累计多少次尝试之后再更新计数并检查是否取消
+const vanityBatch = 256
+
+func searchVanity(ctx context.Context, opts VanityOptions, attempts *atomic.Uint64, found chan<- solana.PrivateKey) {
+	for {
+		for i := 1; i <= vanityBatch; i++ {
+			public, private, err := ed25519.GenerateKey(rand.Reader)
+			if err != nil {
+				continue
+			}
+			if opts.match(base58.Encode(public)) {
+				attempts.Add(uint64(i))
+				select {
+				case found <- solana.PrivateKey(private):
+				default:
+				}
+				return
+			}
+		}
+		attempts.Add(vanityBatch)
+		if ctx.Err() != nil {
+			return
+		}
+	}
+}
+
+func reportVanity(ctx context.Context, opts VanityOptions, attempts *atomic.Uint64, start time.Time) {
+	ticker := time.NewTicker(opts.ProgressInterval)
+	defer ticker.Stop()
+
+	expected := ExpectedVanityAttempts(opts)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			elapsed := time.Since(start)
+			n := attempts.Load()
+			opts.Progress(VanityProgress{
+				Attempts: n,
+				Expected: expected,
+				Elapsed:  elapsed,
+				Rate:     float64(n) / math.Max(elapsed.Seconds(), 1e-9),
+			})
+		}
+	}
+}
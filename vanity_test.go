@@ -0,0 +1,76 @@
+package gosolana
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_ExpectedVanityAttempts(t *testing.T) {
+	require.Equal(t, 58.0*58, ExpectedVanityAttempts(VanityOptions{Prefix: "ab"}))
+	require.Equal(t, 58.0*58*58, ExpectedVanityAttempts(VanityOptions{Prefix: "ab", Suffix: "9"}))
+	// a 和 A 都是base58字符，L 只能匹配 L
+	require.Equal(t, 29.0*58, ExpectedVanityAttempts(VanityOptions{Prefix: "aL", IgnoreCase: true}))
+}
+
+func Test_VanityInvalidPattern(t *testing.T) {
+	ctx := context.Background()
+	for _, opts := range []VanityOptions{
+		{},
+		{Prefix: "0"},
+		{Suffix: "abO"},
+		{Prefix: "I"},
+		{Prefix: "é", IgnoreCase: true},
+		{Prefix: strings.Repeat("a", 45)},
+	} {
+		_, err := GenerateVanity(ctx, opts)
+		require.ErrorIs(t, err, ErrInvalidPattern, "%+v", opts)
+	}
+
+	// 不区分大小写时 l 可以匹配 L
+	require.NoError(t, VanityOptions{Prefix: "l", IgnoreCase: true}.validate())
+}
+
+func Test_GenerateVanity(t *testing.T) {
+	ctx := context.Background()
+
+	result, err := GenerateVanity(ctx, VanityOptions{Prefix: "a", Suffix: "b"})
+	require.NoError(t, err)
+	address := result.PublicKey().String()
+	require.True(t, strings.HasPrefix(address, "a") && strings.HasSuffix(address, "b"), address)
+	require.NotZero(t, result.Attempts)
+	require.NotContains(t, fmt.Sprintf("%v %#v", result, result), result.Pkey())
+
+	result, err = GenerateVanity(ctx, VanityOptions{Prefix: "z", IgnoreCase: true, Workers: 2})
+	require.NoError(t, err)
+	require.Contains(t, "zZ", result.PublicKey().String()[:1])
+
+	node := newTestNode(t, 1)
+	wallet, err := NewWallet(ctx, Option{RpcUrl: node.URL, WsMode: WsDisabled, Pkey: result.Pkey()})
+	require.NoError(t, err)
+	require.Equal(t, result.PublicKey(), wallet.PublicKey())
+}
+
+func Test_GenerateVanityCancel(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+
+	var last atomic.Pointer[VanityProgress]
+	_, err := GenerateVanity(ctx, VanityOptions{
+		Prefix:           "zzzzzzzzzz",
+		ProgressInterval: 20 * time.Millisecond,
+		Progress:         func(p VanityProgress) { last.Store(&p) },
+	})
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+
+	progress := last.Load()
+	require.NotNil(t, progress)
+	require.Equal(t, ExpectedVanityAttempts(VanityOptions{Prefix: "zzzzzzzzzz"}), progress.Expected)
+	require.Positive(t, progress.Attempts)
+	require.Positive(t, progress.Remaining())
+}
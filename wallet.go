@@ -1,16 +1,21 @@
 package gosolana
 
 import (
+	"cmp"
 	"context"
+	"errors"
+	"fmt"
+	"log/slog"
 	"net/http"
+	"time"
 
-	"github.com/go-enols/go-log"
-
-	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/gagliardetto/solana-go"
 	"github.com/gagliardetto/solana-go/programs/token"
 	"github.com/gagliardetto/solana-go/rpc"
 	"github.com/gagliardetto/solana-go/rpc/jsonrpc"
+	"github.com/go-enols/gosolana/hd"
+	"github.com/go-enols/gosolana/keystore"
+	"github.com/go-enols/gosolana/log"
 	"github.com/go-enols/gosolana/ws"
 )
 
@@ -20,99 +25,224 @@ type Wallet struct {
 	wsRpc         *ws.Client
 	HTTPClient    *http.Client
 	Address       string
-	Base58Pkey    string // base58格式的私钥
-	HashPkey      string // hash格式的私钥
 
-	*solana.Wallet
+	signer Signer // 钱包的签名者，私钥不导出，避免被序列化或者打印
 }
 
+// NewWallet 创建一个钱包，配置无效或者连接失败时返回 *OptionError
+//
+// 开启 Option.VerifyGenesis 时节点的创世哈希不一致会返回 ErrGenesisMismatch
 func NewWallet(ctx context.Context, option ...Option) (*Wallet, error) {
-	op := NewDefaultOption(ctx, option...)
-	var (
-		wall *solana.Wallet
-		err  error
-	)
-	if op.Pkey != "" {
-		wall, err = solana.WalletFromPrivateKeyBase58(op.Pkey)
+	op, err := NewOption(ctx, option...)
+	if err != nil {
+		return nil, err
+	}
+	wallet, err := newWallet(ctx, op)
+	if err != nil {
+		op.release()
+		return nil, err
+	}
+	return wallet, nil
+}
+
+func newWallet(ctx context.Context, op Option) (*Wallet, error) {
+	signer := op.Signer
+	if op.Keystore != "" {
+		key, err := keystore.Load(op.Keystore, op.KeystorePassphrase)
+		if err != nil {
+			return nil, &OptionError{Field: "Keystore", Err: err}
+		}
+		signer = NewKeySigner(key)
+	}
+	if op.Mnemonic != "" {
+		key, err := mnemonicKey(op)
 		if err != nil {
+			return nil, &OptionError{Field: "Mnemonic", Err: err}
+		}
+		signer = NewKeySigner(key)
+	}
+	if signer == nil {
+		key, err := solana.PrivateKeyFromBase58(op.Pkey)
+		if err != nil {
+			return nil, &OptionError{Field: "Pkey", Err: errors.New("不是有效的base58私钥")}
+		}
+		signer = NewKeySigner(key)
+	}
+	if op.VerifyGenesis {
+		// 防止把私钥用在错误的集群上，节点池中的每个节点都要校验
+		if err := op.verifyGenesis(ctx); err != nil {
 			return nil, err
 		}
 	}
 
-	log.Printf("成功创建Solana钱包 | %s | %s", wall.PublicKey(), wall.PrivateKey)
+	log.Info("成功创建Solana钱包", "address", signer.PublicKey())
 	return &Wallet{
 		JsonRpcClient: op.JsonRpcClient,
 		rpc:           op.RpcClient,
-		Address:       wall.PublicKey().String(),
-		Base58Pkey:    wall.PrivateKey.String(),
-		HashPkey:      hexutil.Encode(wall.PrivateKey),
-		Wallet:        wall,
+		Address:       signer.PublicKey().String(),
+		signer:        signer,
 		wsRpc:         op.WsClient,
 	}, nil
 }
 
+// mnemonicKey 从助记词派生私钥
+func mnemonicKey(op Option) (solana.PrivateKey, error) {
+	if op.DerivationPath == "" {
+		return hd.FromMnemonic(op.Mnemonic, op.MnemonicPassphrase, op.Account)
+	}
+	seed, err := hd.Seed(op.Mnemonic, op.MnemonicPassphrase)
+	if err != nil {
+		return nil, err
+	}
+	defer clear(seed)
+	return hd.DeriveKey(seed, op.DerivationPath)
+}
+
+// PublicKey 钱包地址
+func (w *Wallet) PublicKey() solana.PublicKey {
+	return w.signer.PublicKey()
+}
+
+// Signer 钱包的签名者
+func (w *Wallet) Signer() Signer {
+	return w.signer
+}
+
+// ExportPrivateKey 导出钱包私钥，调用方需要自行保证私钥不会被打印或者持久化
+//
+// 私钥不在本地的签名者，例如远程签名服务，返回 ErrKeyNotExportable
+func (w *Wallet) ExportPrivateKey() (solana.PrivateKey, error) {
+	if s, ok := w.signer.(*KeySigner); ok {
+		return s.key, nil
+	}
+	return nil, ErrKeyNotExportable
+}
+
+// SignTransaction 使用钱包和 signers 为交易签名，见 SignTransaction
+func (w *Wallet) SignTransaction(ctx context.Context, tx *solana.Transaction, signers ...Signer) error {
+	return SignTransaction(ctx, tx, append([]Signer{w.signer}, signers...)...)
+}
+
+// String 打印钱包时只显示地址
+func (w *Wallet) String() string {
+	return "Wallet(" + w.Address + ")"
+}
+
+// GoString 防止 %#v 打印出私钥
+func (w *Wallet) GoString() string {
+	return w.String()
+}
+
+// LogValue 实现 slog.LogValuer，日志中只记录地址
+func (w *Wallet) LogValue() slog.Value {
+	return slog.StringValue(w.Address)
+}
+
 func (w *Wallet) GetClient() *rpc.Client {
 	return w.rpc
 }
 
+// GetWsClient 返回ws客户端，WsDisabled 模式下返回 nil
 func (w *Wallet) GetWsClient() *ws.Client {
 	return w.wsRpc
 }
 
-func (w *Wallet) SendTransaction(ctx context.Context, instruction []solana.Instruction, signer []solana.PrivateKey) (bool, error) {
-	recentBlockHash, err := w.GetClient().GetLatestBlockhash(ctx, rpc.CommitmentFinalized)
+// GetMultipleAccounts 查询任意数量的账户，自动按照每块100个拆分并行查询，结果与 accounts 的顺序一一对应
+func (w *Wallet) GetMultipleAccounts(ctx context.Context, accounts []solana.PublicKey) ([]AccountResult, error) {
+	return GetMultipleAccounts(ctx, w.GetClient(), accounts)
+}
+
+// SendOptions 发送交易的配置
+type SendOptions struct {
+	// FeePayer 支付手续费的签名者，默认为钱包本身
+	FeePayer Signer
+	// EstimateComputeUnits 发送之前模拟交易，按照实际消耗的计算单元设置上限，见 TxBuilder.EstimateComputeUnits
+	EstimateComputeUnits bool
+	// ComputeUnitMargin 在消耗的计算单元上增加的比例，默认为 DefaultComputeUnitMargin
+	ComputeUnitMargin float64
+	// PriorityFee 不为空时发送之前估算优先费并设置计算单元的价格，见 Wallet.EstimatePriorityFee
+	PriorityFee *PriorityFeeOptions
+
+	// Commitment 等待交易达到的确认等级，默认为 processed
+	Commitment rpc.CommitmentType
+	// RebroadcastInterval 交易确认之前重新广播的间隔，默认为 DefaultRebroadcastInterval
+	RebroadcastInterval time.Duration
+	// SkipPreflight 第一次发送时跳过节点的预检，重新广播时总是跳过
+	SkipPreflight bool
+	// LastValidBlockHeight 交易区块哈希的最后有效区块高度，SendTransaction 会自动设置，
+	// 为0时按照当前区块高度加上 MaxProcessingAge 估算
+	LastValidBlockHeight uint64
+}
+
+// SendTransaction 构造、签名并发送交易，等待交易确认
+//
+// 钱包作为手续费支付者签名，signers 为指令需要的其他签名者，例如新创建的账户或者多签的其他成员，
+// 私钥可以通过 KeySigners 转换。缺少签名者时在发送之前返回 *MissingSignersError
+func (w *Wallet) SendTransaction(ctx context.Context, instruction []solana.Instruction, signers ...Signer) (bool, error) {
+	return w.SendTransactionWithOpts(ctx, instruction, SendOptions{}, signers...)
+}
+
+// SendTransactionWithOpts 与 SendTransaction 相同，可以指定手续费支付者等配置
+//
+// 手续费支付者的私钥不在本地时，使用 NewTransaction 和 PartialSignTransaction 构造部分签名的交易，
+// 交给手续费代付服务完成签名之后再通过 SendSignedTransaction 发送
+func (w *Wallet) SendTransactionWithOpts(ctx context.Context, instruction []solana.Instruction, opts SendOptions, signers ...Signer) (bool, error) {
+	builder := w.NewTxBuilder().AddInstruction(instruction...).AddSigners(signers...)
+	if opts.FeePayer != nil {
+		builder.SetFeePayer(opts.FeePayer)
+	}
+	if opts.EstimateComputeUnits {
+		builder.EstimateComputeUnits(cmp.Or(opts.ComputeUnitMargin, DefaultComputeUnitMargin))
+	}
+	if opts.PriorityFee != nil {
+		builder.EstimatePriorityFee(*opts.PriorityFee)
+	}
+	tx, err := builder.BuildAndSign(ctx)
 	if err != nil {
-		log.Printf("获取Hash失败 | %s", err)
 		return false, err
 	}
+	log.Debug("签名交易输出", "signatures", tx.Signatures)
+	opts.LastValidBlockHeight = builder.LastValidBlockHeight()
+	return w.SendSignedTransactionWithOpts(ctx, tx, opts)
+}
+
+// NewTransaction 使用最新的区块哈希构造一个未签名的交易
+//
+//	payer 手续费支付者
+func (w *Wallet) NewTransaction(ctx context.Context, instruction []solana.Instruction, payer solana.PublicKey) (*solana.Transaction, error) {
+	recentBlockHash, err := w.GetClient().GetLatestBlockhash(ctx, rpc.CommitmentFinalized)
+	if err != nil {
+		log.Error("获取Hash失败", "error", err)
+		return nil, err
+	}
 	// 构造交易
 	tx, err := solana.NewTransaction(
 		instruction,
 		recentBlockHash.Value.Blockhash,
-		solana.TransactionPayer(w.PublicKey()),
+		solana.TransactionPayer(payer),
 	)
 	if err != nil {
-		log.Printf("构建交易失败 | %s", err)
-		return false, err
+		log.Error("构建交易失败", "error", err)
+		return nil, err
 	}
+	return tx, nil
+}
 
-	// 签名交易
-	out, err := tx.Sign(func(key solana.PublicKey) *solana.PrivateKey {
-		if key.Equals(w.PublicKey()) {
-			return &w.Wallet.PrivateKey
-		}
-		return nil
-	})
-	if err != nil {
-		log.Printf("签名交易失败 | %s", err)
-		return false, err
-	}
-	log.Printf("签名交易输出 | %v", out)
-	// 7. 发送交易
-	sig, err := w.GetClient().SendTransactionWithOpts(
-		context.Background(),
-		tx,
-		rpc.TransactionOpts{
-			SkipPreflight:       false,
-			PreflightCommitment: rpc.CommitmentProcessed,
-		},
-	)
-	if err != nil {
-		log.Printf("发送交易失败 | %s", err)
-		return false, err
-	}
-	log.Printf("Transaction Signature: %s", sig)
-	log.Printf("交易详情 | %v", tx) // 打印交易详情
-	result, err := w.GetTransaction(ctx, sig)
-	if err != nil {
-		log.Printf("获取交易状态失败 | %s", err)
-		return false, err
-	}
-	return result, nil
+// PartialSignTransaction 使用钱包和 signers 为交易部分签名，见 PartialSignTransaction
+func (w *Wallet) PartialSignTransaction(ctx context.Context, tx *solana.Transaction, signers ...Signer) error {
+	return PartialSignTransaction(ctx, tx, append([]Signer{w.signer}, signers...)...)
+}
+
+// SendSignedTransaction 发送已经完成签名的交易并等待确认，还有账户没有签名时返回 *MissingSignersError，
+// 见 SendSignedTransactionWithOpts
+func (w *Wallet) SendSignedTransaction(ctx context.Context, tx *solana.Transaction) (bool, error) {
+	return w.SendSignedTransactionWithOpts(ctx, tx, SendOptions{})
 }
 
 // GetTransaction 获取交易状态直到成功为止
 //
+// 没有ws客户端或者订阅失败时通过 getSignatureStatuses 轮询交易状态
+//
 // ctx: 上下文对象，方便后续设置超时等信息
 //
 // sign: 交易广播的sign
@@ -122,33 +252,72 @@ func (w *Wallet) GetTransaction(ctx context.Context, sign solana.Signature, opti
 	if len(option) > 0 {
 		commitment = option[0]
 	}
+	if w.GetWsClient() == nil {
+		return w.pollTransaction(ctx, sign, commitment)
+	}
 	// 等待交易确认
 	sub, err := w.GetWsClient().SignatureSubscribe(
 		sign,
 		commitment,
 	)
 	if err != nil {
-		log.Printf("Failed to subscribe to signature: %v", err)
-		return false, err
+		log.Warn("订阅交易状态失败，改为轮询", "error", err)
+		return w.pollTransaction(ctx, sign, commitment)
 	}
 	defer sub.Unsubscribe()
 
 	for {
 		got, err := sub.Recv(ctx)
 		if err != nil {
-			log.Printf("Error receiving signature status: %v", err)
+			log.Error("接收交易状态失败", "signature", sign, "error", err)
 			return false, err
 		}
 		if got.Value.Err != nil {
-			log.Printf("Transaction failed: %v", got.Value.Err)
-			return false, err
+			log.Warn("交易执行失败", "signature", sign, "error", got.Value.Err)
+			return false, fmt.Errorf("交易执行失败: %v", got.Value.Err)
 		} else {
-			log.Printf("Transaction confirmed | %s", sign.String())
+			log.Info("交易已确认", "signature", sign)
 			return true, nil
 		}
 	}
 }
 
+// pollInterval 轮询交易状态的间隔
+const pollInterval = 500 * time.Millisecond
+
+// pollTransaction 通过 getSignatureStatuses 轮询交易状态，直到达到指定的确认等级
+func (w *Wallet) pollTransaction(ctx context.Context, sign solana.Signature, commitment rpc.CommitmentType) (bool, error) {
+	ticker := time.NewTicker(pollInterval)
+	defer ticker.Stop()
+
+	for {
+		confirmed, err := w.signatureConfirmed(ctx, sign, commitment)
+		if confirmed || err != nil {
+			return confirmed, err
+		}
+
+		select {
+		case <-ctx.Done():
+			return false, ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// confirmationReached 交易的确认状态是否已经达到要求的确认等级
+func confirmationReached(status rpc.ConfirmationStatusType, commitment rpc.CommitmentType) bool {
+	rank := map[string]int{
+		string(rpc.ConfirmationStatusProcessed): 1,
+		string(rpc.ConfirmationStatusConfirmed): 2,
+		string(rpc.ConfirmationStatusFinalized): 3,
+	}
+	want, ok := rank[string(commitment)]
+	if !ok {
+		want = rank[string(rpc.CommitmentFinalized)]
+	}
+	return rank[string(status)] >= want
+}
+
 func (w *Wallet) GetTokenAccounts(walletAddress string) ([]solana.PublicKey, error) {
 	pubKey, _ := solana.PublicKeyFromBase58(walletAddress)
 
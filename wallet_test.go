@@ -0,0 +1,124 @@
+package gosolana
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+
+	"github.com/gagliardetto/solana-go"
+	"github.com/gagliardetto/solana-go/rpc"
+	"github.com/go-enols/gosolana/keystore"
+	"github.com/go-enols/gosolana/log"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_WalletHidesPrivateKey(t *testing.T) {
+	defer log.SetLogger(log.Logger())
+	var buf bytes.Buffer
+	log.SetLogger(slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug})))
+
+	key := solana.NewWallet().PrivateKey
+	node := newTestNode(t, 1)
+	opt := Option{RpcUrl: node.URL, WsMode: WsDisabled, Pkey: key.String()}
+	wallet, err := NewWallet(context.Background(), opt)
+	require.NoError(t, err)
+	require.Equal(t, key.PublicKey(), wallet.PublicKey())
+	exported, err := wallet.ExportPrivateKey()
+	require.NoError(t, err)
+	require.Equal(t, key, exported)
+
+	log.Info("wallet", "wallet", wallet, "option", opt)
+	printed := fmt.Sprintf("%v %+v %#v %v %+v %#v", wallet, wallet, wallet, opt, opt, opt)
+	for _, out := range []string{buf.String(), printed} {
+		require.NotContains(t, out, key.String())
+		require.Contains(t, out, key.PublicKey().String())
+	}
+}
+
+func Test_WalletFromKeystore(t *testing.T) {
+	ctx := context.Background()
+	node := newTestNode(t, 1)
+	dir := t.TempDir()
+	key := solana.NewWallet().PrivateKey
+
+	encrypted := filepath.Join(dir, "wallet.json")
+	require.NoError(t, keystore.WriteFile(encrypted, key, "passphrase", keystore.LightOptions))
+	keygen := filepath.Join(dir, "id.json")
+	require.NoError(t, keystore.WriteKeygenFile(keygen, key))
+
+	for _, path := range []string{encrypted, keygen} {
+		wallet, err := NewWallet(ctx, Option{RpcUrl: node.URL, WsMode: WsDisabled, Keystore: path, KeystorePassphrase: "passphrase"})
+		require.NoError(t, err)
+		require.Equal(t, key.PublicKey(), wallet.PublicKey())
+	}
+
+	_, err := NewWallet(ctx, Option{RpcUrl: node.URL, WsMode: WsDisabled, Keystore: encrypted, KeystorePassphrase: "wrong"})
+	var optErr *OptionError
+	require.ErrorAs(t, err, &optErr)
+	require.Equal(t, "Keystore", optErr.Field)
+	require.ErrorIs(t, err, keystore.ErrDecrypt)
+
+	_, err = NewWallet(ctx, Option{RpcUrl: node.URL, WsMode: WsDisabled, Keystore: keygen, Pkey: key.String()})
+	require.ErrorAs(t, err, &optErr)
+	require.NotContains(t, err.Error(), key.String())
+}
+
+func Test_WalletFromMnemonic(t *testing.T) {
+	ctx := context.Background()
+	node := newTestNode(t, 1)
+	mnemonic := "abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon about"
+
+	wallet, err := NewWallet(ctx, Option{RpcUrl: node.URL, WsMode: WsDisabled, Mnemonic: mnemonic})
+	require.NoError(t, err)
+	require.Equal(t, "HAgk14JpMQLgt6rVgv7cBQFJWFto5Dqxi472uT3DKpqk", wallet.Address)
+
+	second, err := NewWallet(ctx, Option{RpcUrl: node.URL, WsMode: WsDisabled, Mnemonic: mnemonic, Account: 1})
+	require.NoError(t, err)
+	custom, err := NewWallet(ctx, Option{RpcUrl: node.URL, WsMode: WsDisabled, Mnemonic: mnemonic, DerivationPath: "m/44'/501'/1'/0'"})
+	require.NoError(t, err)
+	require.Equal(t, second.PublicKey(), custom.PublicKey())
+	require.NotEqual(t, wallet.PublicKey(), second.PublicKey())
+
+	_, err = NewWallet(ctx, Option{RpcUrl: node.URL, WsMode: WsDisabled, Mnemonic: "abandon abandon secret"})
+	var optErr *OptionError
+	require.ErrorAs(t, err, &optErr)
+	require.Equal(t, "Mnemonic", optErr.Field)
+	require.NotContains(t, err.Error(), "secret")
+}
+
+func Test_GetTransactionPolling(t *testing.T) {
+	ctx := context.Background()
+	node, _ := newTxNode(t)
+	var polls atomic.Int64
+	next := node.handler
+	node.handler = func(w http.ResponseWriter, req map[string]any) bool {
+		if req["method"] == "getSignatureStatuses" {
+			polls.Add(1)
+		}
+		return next(w, req)
+	}
+
+	// 没有ws客户端时轮询交易状态
+	wallet, err := NewWallet(ctx, Option{RpcUrl: node.URL, WsMode: WsDisabled})
+	require.NoError(t, err)
+	require.Nil(t, wallet.GetWsClient())
+	ok, err := wallet.GetTransaction(ctx, solana.Signature{1}, rpc.CommitmentConfirmed)
+	require.NoError(t, err)
+	require.True(t, ok)
+	require.Equal(t, int64(1), polls.Load())
+
+	// 延迟连接的ws无法连接时改为轮询
+	wallet, err = NewWallet(ctx, Option{RpcUrl: node.URL, WsUrl: "ws://127.0.0.1:1", WsMode: WsLazy})
+	require.NoError(t, err)
+	require.NotNil(t, wallet.GetWsClient())
+	ok, err = wallet.GetTransaction(ctx, solana.Signature{1}, rpc.CommitmentConfirmed)
+	require.NoError(t, err)
+	require.True(t, ok)
+	require.Equal(t, int64(2), polls.Load())
+	require.False(t, wallet.GetWsClient().Connected())
+}
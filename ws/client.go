@@ -28,10 +28,9 @@ import (
 	"time"
 
 	"github.com/buger/jsonparser"
-	"github.com/go-enols/go-log"
+	"github.com/go-enols/gosolana/log"
 	"github.com/gorilla/rpc/v2/json2"
 	"github.com/gorilla/websocket"
-	"go.uber.org/zap"
 )
 
 var ErrSubscriptionClosed = errors.New("subscription closed")
@@ -50,6 +49,8 @@ type Client struct {
 	shortID                 bool
 	httpHeader              http.Header
 	dialer                  *websocket.Dialer
+	connectLock             sync.Mutex         // 延迟连接时保证只建立一次连接
+	closeCancel             context.CancelFunc // 延迟连接模式下 Close 时停止连接和重连
 }
 
 const (
@@ -69,6 +70,9 @@ func Connect(ctx context.Context, rpcEndpoint string) (c *Client, err error) {
 // ConnectWithOptions 创建新的websocket客户端连接到指定端点
 // 可选的http头参数可用于传递基本认证参数
 // 参考 https://github.com/gorilla/websocket/issues/209
+//
+// opt.Lazy 为 true 时不会立即连接，第一次订阅时才建立连接。连接可能发生在创建之后很久，
+// 因此不受 ctx 取消和超时的影响，只会在 Close 之后停止
 func ConnectWithOptions(ctx context.Context, rpcEndpoint string, opt *Options) (c *Client, err error) {
 	c = &Client{
 		parentCtx:               ctx,
@@ -102,9 +106,36 @@ func ConnectWithOptions(ctx context.Context, rpcEndpoint string, opt *Options) (
 	}
 	c.httpHeader = httpHeader
 	c.dialer = dialer
+	if opt != nil && opt.Lazy {
+		c.parentCtx, c.closeCancel = context.WithCancel(context.WithoutCancel(ctx))
+		return c, nil
+	}
 	return c, c.reconnect()
 }
 
+// Connected 是否已经建立过连接
+func (c *Client) Connected() bool {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+	return c.conn != nil
+}
+
+// ensureConnected 延迟连接模式下第一次订阅时建立连接
+func (c *Client) ensureConnected() error {
+	c.connectLock.Lock()
+	defer c.connectLock.Unlock()
+	if c.Connected() {
+		return nil
+	}
+	if err := c.reconnect(); err != nil {
+		return err
+	}
+	if !c.Connected() {
+		return fmt.Errorf("new ws client: %w", c.parentCtx.Err())
+	}
+	return nil
+}
+
 func (c *Client) reconnect() error {
 	c.lock.Lock()
 	defer c.lock.Unlock()
@@ -129,7 +160,7 @@ func (c *Client) reconnect() error {
 			} else {
 				err = fmt.Errorf("new ws client: dial: %w", err)
 			}
-			log.Error(err)
+			log.Error("ws连接失败", "error", err)
 			return err
 		}
 		c.connCtx, c.connCtxCancel = context.WithCancel(context.Background())
@@ -164,6 +195,12 @@ func (c *Client) sendPing() {
 func (c *Client) Close() {
 	c.lock.Lock()
 	defer c.lock.Unlock()
+	if c.closeCancel != nil {
+		c.closeCancel()
+	}
+	if c.conn == nil { // 延迟连接模式下还没有建立连接
+		return
+	}
 	c.connCtxCancel()
 	c.conn.Close()
 }
@@ -225,51 +262,45 @@ func (c *Client) handleNewSubscriptionMessage(requestID, subID uint64) {
 	c.lock.Lock()
 	defer c.lock.Unlock()
 
-	if traceEnabled {
-		zlog.Debug("received new subscription message",
-			zap.Uint64("message_id", requestID),
-			zap.Uint64("subscription_id", subID),
-		)
-	}
+	log.Debug("received new subscription message",
+		"message_id", requestID,
+		"subscription_id", subID,
+	)
 
 	callBack, found := c.subscriptionByRequestID[requestID]
 	if !found {
-		zlog.Error("cannot find websocket message handler for a new stream.... this should not happen",
-			zap.Uint64("request_id", requestID),
-			zap.Uint64("subscription_id", subID),
+		log.Error("cannot find websocket message handler for a new stream.... this should not happen",
+			"request_id", requestID,
+			"subscription_id", subID,
 		)
 		return
 	}
 	callBack.subID = subID
 	c.subscriptionByWSSubID[subID] = callBack
 
-	zlog.Debug("registered ws subscription",
-		zap.Uint64("subscription_id", subID),
-		zap.Uint64("request_id", requestID),
-		zap.Int("subscription_count", len(c.subscriptionByWSSubID)),
+	log.Debug("registered ws subscription",
+		"subscription_id", subID,
+		"request_id", requestID,
+		"subscription_count", len(c.subscriptionByWSSubID),
 	)
 	return
 }
 
 func (c *Client) handleSubscriptionMessage(subID uint64, message []byte) {
-	if traceEnabled {
-		zlog.Debug("received subscription message",
-			zap.Uint64("subscription_id", subID),
-		)
-	}
+	log.Debug("received subscription message", "subscription_id", subID)
 
 	c.lock.RLock()
 	sub, found := c.subscriptionByWSSubID[subID]
 	c.lock.RUnlock()
 	if !found {
-		zlog.Warn("unable to find subscription for ws message", zap.Uint64("subscription_id", subID))
+		log.Warn("unable to find subscription for ws message", "subscription_id", subID)
 		return
 	}
 
 	// Decode the message using the subscription-provided decoderFunc.
 	result, err := sub.decoderFunc(message)
 	if err != nil {
-		fmt.Println("*****************************")
+		log.Error("unable to decode ws subscription message", "subscription_id", subID, "error", err)
 		c.closeSubscription(sub.req.ID, fmt.Errorf("unable to decode client response: %w", err))
 		return
 	}
@@ -277,8 +308,8 @@ func (c *Client) handleSubscriptionMessage(subID uint64, message []byte) {
 	// this cannot be blocking or else
 	// we  will no read any other message
 	if len(sub.stream) >= cap(sub.stream) {
-		zlog.Warn("closing ws client subscription... not consuming fast en ought",
-			zap.Uint64("request_id", sub.req.ID),
+		log.Warn("closing ws client subscription... not consuming fast en ought",
+			"request_id", sub.req.ID,
 		)
 		c.closeSubscription(sub.req.ID, fmt.Errorf("reached channel max capacity %d", len(sub.stream)))
 		return
@@ -315,8 +346,8 @@ func (c *Client) closeSubscription(reqID uint64, err error) {
 
 	err = c.unsubscribe(sub.subID, sub.unsubscribeMethod)
 	if err != nil {
-		zlog.Warn("unable to send rpc unsubscribe call",
-			zap.Error(err),
+		log.Warn("unable to send rpc unsubscribe call",
+			"error", err,
 		)
 	}
 
@@ -346,6 +377,10 @@ func (c *Client) subscribe(
 	unsubscribeMethod string,
 	decoderFunc decoderFunc,
 ) (*Subscription, error) {
+	if err := c.ensureConnected(); err != nil {
+		return nil, err
+	}
+
 	c.lock.Lock()
 	defer c.lock.Unlock()
 
@@ -365,9 +400,9 @@ func (c *Client) subscribe(
 	)
 
 	c.subscriptionByRequestID[req.ID] = sub
-	zlog.Info("added new subscription to websocket client", zap.Int("count", len(c.subscriptionByRequestID)))
+	log.Info("added new subscription to websocket client", "count", len(c.subscriptionByRequestID))
 
-	zlog.Debug("writing data to conn", zap.String("data", string(data)))
+	log.Debug("writing data to conn", "data", string(data))
 	c.conn.SetWriteDeadline(time.Now().Add(writeWait))
 	err = c.conn.WriteMessage(websocket.TextMessage, data)
 	if err != nil {
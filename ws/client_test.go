@@ -21,21 +21,28 @@ import (
 	"context"
 	"encoding/base64"
 	"fmt"
+	"log/slog"
 	"net/http"
+	"net/http/httptest"
 	"os"
+	"strings"
+	"sync/atomic"
 	"testing"
 	"time"
 
 	"github.com/gagliardetto/solana-go"
+	"github.com/gagliardetto/solana-go/rpc"
 	"github.com/gagliardetto/solana-go/text"
+	"github.com/go-enols/gosolana/log"
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
-	"go.uber.org/zap"
 )
 
 func Test_AccountSubscribe(t *testing.T) {
 	t.Skip("Never ending test, revisit me to not depend on actual network calls, or hide between env flag")
 
-	zlog, _ = zap.NewDevelopment()
+	log.SetLogger(slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelDebug})))
 
 	c, err := Connect(context.Background(), "ws://api.mainnet-beta.solana.com:80")
 	defer c.Close()
@@ -58,7 +65,7 @@ func Test_AccountSubscribe(t *testing.T) {
 
 func Test_AccountSubscribeWithHttpHeader(t *testing.T) {
 	t.Skip("Never ending test, revisit me to not depend on actual network calls, or hide between env flag")
-	zlog, _ = zap.NewDevelopment()
+	log.SetLogger(slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelDebug})))
 
 	// Pass in bogus websocket authentication credentials
 	wssUser := "john"
@@ -114,7 +121,7 @@ func Test_AccountSubscribeWithHttpHeader(t *testing.T) {
 func Test_ProgramSubscribe(t *testing.T) {
 	t.Skip("Never ending test, revisit me to not depend on actual network calls, or hide between env flag")
 
-	zlog, _ = zap.NewDevelopment()
+	log.SetLogger(slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelDebug})))
 
 	fmt.Println("Dialing")
 	c, err := Connect(context.Background(), "wss://solana-api.projectserum.com")
@@ -139,7 +146,7 @@ func Test_ProgramSubscribe(t *testing.T) {
 func Test_SlotSubscribe(t *testing.T) {
 	t.Skip("Never ending test, revisit me to not depend on actual network calls, or hide between env flag")
 
-	zlog, _ = zap.NewDevelopment()
+	log.SetLogger(slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelDebug})))
 
 	c, err := Connect(context.Background(), "ws://api.mainnet-beta.solana.com:80")
 	defer c.Close()
@@ -156,3 +163,48 @@ func Test_SlotSubscribe(t *testing.T) {
 	fmt.Println("data received: ", data.Parent)
 	return
 }
+
+func Test_LazyConnect(t *testing.T) {
+	var dials atomic.Int64
+	requests := make(chan string, 10)
+	upgrader := websocket.Upgrader{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		dials.Add(1)
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if !assert.NoError(t, err) {
+			return
+		}
+		defer conn.Close()
+		for {
+			_, message, err := conn.ReadMessage()
+			if err != nil {
+				return
+			}
+			requests <- string(message)
+		}
+	}))
+	defer server.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond)
+	c, err := ConnectWithOptions(ctx, "ws"+strings.TrimPrefix(server.URL, "http"), &Options{Lazy: true})
+	require.NoError(t, err)
+	defer c.Close()
+	require.False(t, c.Connected())
+	require.Zero(t, dials.Load(), "第一次订阅之前不应该建立连接")
+
+	// 创建时的 ctx 已经超时，不应该影响之后的连接
+	cancel()
+	_, err = c.SignatureSubscribe(solana.Signature{1}, rpc.CommitmentConfirmed)
+	require.NoError(t, err)
+	require.True(t, c.Connected())
+	select {
+	case message := <-requests:
+		require.Contains(t, message, "signatureSubscribe")
+	case <-time.After(time.Second):
+		t.Fatal("没有收到订阅请求")
+	}
+
+	_, err = c.SignatureSubscribe(solana.Signature{2}, rpc.CommitmentConfirmed)
+	require.NoError(t, err)
+	require.Equal(t, int64(1), dials.Load(), "只应该建立一次连接")
+}
@@ -74,6 +74,7 @@ type Options struct {
 	HttpHeader       http.Header
 	HandshakeTimeout time.Duration
 	ShortID          bool // some RPC do not support int63/uint64 id, so need to enable it to rand a int31/uint32 id
+	Lazy             bool // 创建客户端时不建立连接，第一次订阅时才连接，连接不受创建时 ctx 的取消和超时影响
 }
 
 var DefaultHandshakeTimeout = 45 * time.Second